@@ -0,0 +1,151 @@
+package planner
+
+import "fmt"
+
+// LatestStart is the latest schedule of an attribution that still completes its task by a target day
+type LatestStart struct {
+	// path of the task the attribution belongs to, and its key, its id preceded by # or its path, see FindTask
+	Path  string
+	Key   string
+	DevId DeveloperId
+	// the attribution of the planning, with its forecasted days
+	Attribution *Attribution
+	// the attribution must start on this day at the latest
+	FirstDay Day
+	LastDay  Day
+	// the attribution must start before the developer does, so the task cannot be completed by the target day.
+	// The days before their start are counted as working days, at their utilization, to compute the latest start.
+	BeforeStart bool
+}
+
+// FindTask returns the task with the given key: its id preceded by #, such as #FEAT-1, or its path, made of the
+// project name, the names of the parents of the task and its own name, separated by " / ". It also returns the
+// project the task belongs to. A path shared by several tasks needs to be replaced by the id of the task.
+func FindTask(planning *Planning, key string) (*Task, string, error) {
+	var found *Task
+	var foundProject string
+	matches := 0
+	walkTaskPaths(planning, func(project string, taskPath string, task *Task) {
+		if taskKey(task.Id, taskPath) == key || taskPath == key {
+			if found == nil {
+				found = task
+				foundProject = project
+			}
+			matches++
+		}
+	})
+	if found == nil {
+		return nil, "", fmt.Errorf("task %s does not exist", key)
+	}
+	if matches > 1 {
+		return nil, "", fmt.Errorf("task %s is ambiguous, and needs to be designated by its id", key)
+	}
+	return found, foundProject, nil
+}
+
+// ScheduleBackward computes, for every attribution of the task and its subtasks, the latest days it can be worked on
+// so that the task is completed by the target day. It uses the same rules as ForecastCompletion, in reverse: each
+// developer works on the attributions in tree order, on their working days only, and at their utilization,
+// ramp-up included. The task is designated by its key, see FindTask.
+// Only the task is scheduled: the other work of the developers, such as the tasks forecast before it, is ignored, so
+// the latest starts assume the developers are free to work on the task until the target day.
+// Latest starts are sorted in tree order, then in the order of the attributions.
+func ScheduleBackward(planning *Planning, key string, target Day) ([]*LatestStart, error) {
+	task, project, err := FindTask(planning, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// the paths of the attributions start with the one of the task, whatever its key
+	var path string
+	walkTaskPaths(planning, func(_ string, taskPath string, t *Task) {
+		if t == task {
+			path = taskPath
+		}
+	})
+
+	devMap := make(map[DeveloperId]*Developer, len(planning.Developers))
+	for _, developer := range planning.Developers {
+		devMap[developer.Id] = developer
+	}
+
+	// the public holidays are generated up to the target, on a copy of the planning
+	calendar := NewCalendar(planning.withPublicHolidays(target))
+
+	type taskAttribution struct {
+		path        string
+		key         string
+		devId       DeveloperId
+		attribution *Attribution
+	}
+	attributions := make([]taskAttribution, 0)
+	var walk func(path string, task *Task)
+	walk = func(path string, task *Task) {
+		for _, devId := range task.DeveloperIds() {
+			attributions = append(attributions, taskAttribution{path, taskKey(task.Id, path), devId, task.Attributions[devId]})
+		}
+		for _, subtask := range task.Subtasks {
+			walk(path+" / "+subtask.Name, subtask)
+		}
+	}
+	walk(path, task)
+
+	// devToEarliestDay is the mirror of the devToLatestDay of ForecastCompletion: going through the attributions in
+	// reverse order, it is decremented until all the effort days of the attributions fit before the target day
+	devToEarliestDay := make(map[DeveloperId]Day)
+
+	starts := make([]*LatestStart, len(attributions))
+	for i := len(attributions) - 1; i >= 0; i-- {
+		a := attributions[i]
+		developer, prs := devMap[a.devId]
+		if !prs {
+			return nil, fmt.Errorf("developer %s mentioned in task %s does not exist", a.devId, a.path)
+		}
+
+		if _, prs := devToEarliestDay[a.devId]; !prs {
+			devToEarliestDay[a.devId] = target
+		}
+
+		utilization := developer.projectUtilization(project, planning.RampUp)
+		var lastDay *Day
+		firstDay := devToEarliestDay[a.devId]
+		latestDay := firstDay
+		effort := 0.0
+		beforeStart := false
+		for effort < float64(a.attribution.EffortDays)-effortEpsilon {
+			day := devToEarliestDay[a.devId]
+			if latestDay-day > maxAttributionDays {
+				return nil, fmt.Errorf("%s cannot be scheduled, as developer %s does not work", a.path, a.devId)
+			}
+			working := calendar.IsWorkingDay(a.devId, day)
+			// the days before the developer starts are counted as if they worked, and the latest start is flagged
+			if developer.Starts != nil && day < *developer.Starts {
+				beforeStart = true
+				working = !calendar.offDays[a.devId][day] && !isWeekEnd(day)
+			}
+			if working {
+				effort += utilization(day)
+				firstDay = day
+				if lastDay == nil {
+					lastDay = &day
+				}
+			}
+			devToEarliestDay[a.devId] = day - 1
+		}
+		if lastDay == nil {
+			lastDay = &firstDay
+		}
+
+		starts[i] = &LatestStart{
+			Path:        a.path,
+			Key:         a.key,
+			DevId:       a.devId,
+			Attribution: a.attribution,
+			FirstDay:    firstDay,
+			LastDay:     *lastDay,
+			BeforeStart: beforeStart,
+		}
+	}
+
+	return starts, nil
+}
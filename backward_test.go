@@ -0,0 +1,118 @@
+package planner
+
+import "testing"
+
+func TestScheduleBackward(t *testing.T) {
+	subtask := &Task{
+		Name: "subtask",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 2},
+		},
+	}
+	task := &Task{
+		Name: "task",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 1},
+			"dev2": {EffortDays: 1},
+		},
+		Subtasks: []*Task{subtask},
+	}
+	planning := &Planning{
+		StartDay: 4,
+		Holidays: []Day{15},
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 1, OffDays: []Day{12}},
+			{Id: "dev2", Utilization: 0.5},
+		},
+		Tasks: []*Task{task},
+	}
+
+	starts, err := ScheduleBackward(planning, "task", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// saturdays + sundays: 9, 10, 16, 17
+	// holidays: 15
+	// subtask:
+	// dev1 (2d): 13, 14
+	// task:
+	// dev1 (1d): 11 (12 is off)
+	// dev2 (1d / 0.5 = 2d): 13, 14
+	exp := []LatestStart{
+		{Path: "task", Key: "task", DevId: "dev1", Attribution: task.Attributions["dev1"], FirstDay: 11, LastDay: 11},
+		{Path: "task", Key: "task", DevId: "dev2", Attribution: task.Attributions["dev2"], FirstDay: 13, LastDay: 14},
+		{Path: "task / subtask", Key: "task / subtask", DevId: "dev1", Attribution: subtask.Attributions["dev1"], FirstDay: 13, LastDay: 14},
+	}
+
+	if len(starts) != len(exp) {
+		t.Fatalf("exp %d latest starts, got %d", len(exp), len(starts))
+	}
+	for i, start := range starts {
+		if *start != exp[i] {
+			t.Errorf("exp %+v, got %+v", exp[i], *start)
+		}
+	}
+
+	if _, err := ScheduleBackward(planning, "unknown", 16); err == nil {
+		t.Errorf("exp an error for an unknown task")
+	}
+
+	// a latest start before the developer starts is flagged, rather than reported as an error
+	devStarts := Day(13)
+	planning.Developers[0].Starts = &devStarts
+	starts, err = ScheduleBackward(planning, "task", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !starts[0].BeforeStart || starts[0].FirstDay != 11 || starts[1].BeforeStart || starts[2].BeforeStart {
+		t.Errorf("exp only the latest start of dev1 on task to be flagged, got %+v, %+v and %+v", *starts[0], *starts[1], *starts[2])
+	}
+}
+
+func TestFindTask(t *testing.T) {
+	first := &Task{Id: "T-1", Name: "task"}
+	second := &Task{Id: "T-2", Name: "task", Subtasks: []*Task{{Name: "subtask"}}}
+	planning := &Planning{Tasks: []*Task{first, second}}
+
+	if task, _, err := FindTask(planning, "#T-2"); err != nil || task != second {
+		t.Errorf("exp the task with the id, got %+v and %v", task, err)
+	}
+	if task, _, err := FindTask(planning, "task / subtask"); err != nil || task != second.Subtasks[0] {
+		t.Errorf("exp the task with the path, got %+v and %v", task, err)
+	}
+	if _, _, err := FindTask(planning, "task"); err == nil {
+		t.Errorf("exp an error for a path shared by several tasks")
+	}
+
+	// the attributions of a task designated by its id get its path, and its key
+	second.Subtasks[0].Attributions = map[DeveloperId]*Attribution{"dev1": {EffortDays: 1}}
+	planning.Developers = []*Developer{{Id: "dev1", Utilization: 1}}
+	starts, err := ScheduleBackward(planning, "#T-2", 16)
+	if err != nil || len(starts) != 1 || starts[0].Path != "task / subtask" || starts[0].Key != "task / subtask" {
+		t.Errorf("exp the latest start of the subtask, got %v and %v", starts, err)
+	}
+}
+
+func TestScheduleBackwardDuplicateSubtasks(t *testing.T) {
+	first := &Task{Name: "subtask", Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 1}}}
+	second := &Task{Name: "subtask", Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 1}}}
+	planning := &Planning{
+		StartDay:   4,
+		Developers: []*Developer{{Id: "dev1", Utilization: 1}},
+		Tasks:      []*Task{{Name: "task", Subtasks: []*Task{first, second}}},
+	}
+	ForecastCompletion(planning)
+
+	// subtasks sharing a path are told apart by their attribution, rather than looked up again by path
+	starts, err := ScheduleBackward(planning, "task", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(starts) != 2 || starts[0].Attribution != first.Attributions["dev1"] || starts[1].Attribution != second.Attributions["dev1"] {
+		t.Fatalf("exp the latest starts of both subtasks, got %v", starts)
+	}
+	if *starts[1].Attribution.FirstDay != 5 {
+		t.Errorf("exp the second subtask forecasted on 5, got %d", *starts[1].Attribution.FirstDay)
+	}
+}
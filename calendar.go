@@ -0,0 +1,142 @@
+package planner
+
+import "fmt"
+
+// Calendar tells which days the developers of a planning work, and how much. It is built from the planning's
+// weekends, holidays, holiday calendars, off days, support weeks, start and leave days, utilization and ramp-up, and
+// is the one the forecast uses, so that other tools get the same answers.
+type Calendar struct {
+	rampUp     []RampUpStep
+	developers map[DeveloperId]*Developer
+	// all the non-worked days of each developer, weekends excepted: holidays, off days and support weeks
+	offDays map[DeveloperId]map[Day]bool
+}
+
+// NewCalendar builds the calendar of the developers of the planning. Changes made to the planning afterwards are not
+// taken into account.
+func NewCalendar(planning *Planning) *Calendar {
+	developers := make(map[DeveloperId]*Developer, len(planning.Developers))
+	offDays := make(map[DeveloperId]map[Day]bool, len(planning.Developers))
+	for _, developer := range planning.Developers {
+		developers[developer.Id] = developer
+		offDays[developer.Id] = make(map[Day]bool)
+	}
+
+	// holidays
+	for _, holiday := range planning.Holidays {
+		for _, developer := range planning.Developers {
+			offDays[developer.Id][holiday] = true
+		}
+	}
+
+	// holiday calendars
+	for _, developer := range planning.Developers {
+		for _, calendar := range developer.Calendars {
+			for _, holiday := range planning.Calendars[calendar] {
+				offDays[developer.Id][holiday] = true
+			}
+		}
+	}
+
+	// off days
+	for _, developer := range planning.Developers {
+		for _, day := range developer.OffDays {
+			offDays[developer.Id][day] = true
+		}
+	}
+
+	// support weeks
+	for _, week := range planning.SupportWeeks {
+		if offDays[week.DevId] == nil {
+			continue
+		}
+		for i := week.FirstDay; i <= week.LastDay; i++ {
+			offDays[week.DevId][i] = true
+		}
+	}
+
+	return &Calendar{
+		rampUp:     planning.RampUp,
+		developers: developers,
+		offDays:    offDays,
+	}
+}
+
+// IsWorkingDay tells whether the developer works on the day: a weekday that is not one of their holidays, off days
+// or support weeks, between their start and leave days. The forecast schedules attributions on these days only.
+func (calendar *Calendar) IsWorkingDay(devId DeveloperId, day Day) bool {
+	developer, prs := calendar.developers[devId]
+	if !prs {
+		return false
+	}
+	if developer.Starts != nil && day < *developer.Starts {
+		return false
+	}
+	if calendar.hasLeft(devId, day) {
+		return false
+	}
+	return !calendar.offDays[devId][day] && !isWeekEnd(day)
+}
+
+// hasLeft tells whether the developer does not work anymore on the day or any day after it, because they left or
+// are not part of the planning
+func (calendar *Calendar) hasLeft(devId DeveloperId, day Day) bool {
+	developer, prs := calendar.developers[devId]
+	return !prs || (developer.Leaves != nil && day > *developer.Leaves)
+}
+
+// isOffDay tells whether a weekday is not worked by the developer, because of a holiday, an off day or a support week
+func (calendar *Calendar) isOffDay(devId DeveloperId, day Day) bool {
+	return calendar.offDays[devId][day] && !isWeekEnd(day)
+}
+
+// WorkingDaysBetween counts the working days of the developer between from and to, inclusive
+func (calendar *Calendar) WorkingDaysBetween(devId DeveloperId, from Day, to Day) int {
+	days := 0
+	for day := from; day <= to; day++ {
+		if calendar.IsWorkingDay(devId, day) {
+			days++
+		}
+	}
+	return days
+}
+
+// Capacity returns the man-days the developer can work between from and to, inclusive: their working days weighted by
+// their utilization and ramp-up, all projects included
+func (calendar *Calendar) Capacity(devId DeveloperId, from Day, to Day) float64 {
+	capacity := 0.0
+	for day := from; day <= to; day++ {
+		if calendar.IsWorkingDay(devId, day) {
+			capacity += calendar.developers[devId].utilizationOn(day, calendar.rampUp)
+		}
+	}
+	return capacity
+}
+
+// AddWorkingDays returns the day that comes the given number of working days of the developer after the day, or
+// before it when the number is negative. It fails when the developer does not have that many working days left.
+func (calendar *Calendar) AddWorkingDays(devId DeveloperId, day Day, days int) (Day, error) {
+	developer, prs := calendar.developers[devId]
+	if !prs {
+		return 0, fmt.Errorf("developer %s does not exist", devId)
+	}
+
+	step := Day(1)
+	if days < 0 {
+		step = -1
+		days = -days
+	}
+	for days > 0 {
+		day += step
+		if step > 0 && developer.Leaves != nil && day > *developer.Leaves {
+			return 0, fmt.Errorf("developer %s leaves on %s", devId, DayToDate(*developer.Leaves))
+		}
+		if step < 0 && developer.Starts != nil && day < *developer.Starts {
+			return 0, fmt.Errorf("developer %s starts on %s", devId, DayToDate(*developer.Starts))
+		}
+		if calendar.IsWorkingDay(devId, day) {
+			days--
+		}
+	}
+	return day, nil
+}
@@ -0,0 +1,48 @@
+package planner
+
+import "testing"
+
+func TestCalendar(t *testing.T) {
+	// 01/01/1970 is a Thursday
+	starts := Day(1)
+	leaves := Day(14)
+	planning := &Planning{
+		StartDay: 0,
+		Holidays: Days{5},
+		Developers: []*Developer{
+			{Id: "dev1", OffDays: Days{6}, Starts: &starts, Leaves: &leaves, Utilization: 0.5},
+		},
+		SupportWeeks: []*SupportWeek{{FirstDay: 11, LastDay: 12, DevId: "dev1"}},
+	}
+	calendar := NewCalendar(planning)
+
+	// working days: 1 (Friday), 4 (Monday), 7, 8 (Thursday, Friday), 13, 14
+	working := map[Day]bool{1: true, 4: true, 7: true, 8: true, 13: true, 14: true}
+	for day := Day(-1); day <= 16; day++ {
+		if act := calendar.IsWorkingDay("dev1", day); act != working[day] {
+			t.Errorf("day %d: exp working %v, got %v", day, working[day], act)
+		}
+	}
+	if calendar.IsWorkingDay("dev2", 1) {
+		t.Errorf("exp an unknown developer not to work")
+	}
+
+	if days := calendar.WorkingDaysBetween("dev1", 0, 9); days != 4 {
+		t.Errorf("exp 4 working days, got %d", days)
+	}
+	if capacity := calendar.Capacity("dev1", 0, 9); capacity != 2 {
+		t.Errorf("exp a capacity of 2, got %f", capacity)
+	}
+
+	day, err := calendar.AddWorkingDays("dev1", 1, 3)
+	if err != nil || day != 8 {
+		t.Errorf("exp day 8, got %d (%v)", day, err)
+	}
+	day, err = calendar.AddWorkingDays("dev1", 8, -2)
+	if err != nil || day != 4 {
+		t.Errorf("exp day 4, got %d (%v)", day, err)
+	}
+	if _, err := calendar.AddWorkingDays("dev1", 8, 3); err == nil {
+		t.Errorf("exp an error when the developer leaves before")
+	}
+}
@@ -0,0 +1,114 @@
+package planner
+
+import "math"
+
+// WeekCapacity is the capacity of a developer during an ISO week, expressed in man-days
+type WeekCapacity struct {
+	DevId DeveloperId `json:"developer"`
+	Year  int         `json:"year"`
+	Week  int         `json:"week"`
+	// days worked during the week, after holidays, off days, support weeks and start and leave dates
+	WorkDays int `json:"workDays"`
+	// work days weighted by the developer's utilization
+	Available float64 `json:"available"`
+	// part of the available days taken by the attributions of the forecast
+	Allocated float64 `json:"allocated"`
+}
+
+// Free returns the part of the available days that is not allocated to any attribution
+func (capacity *WeekCapacity) Free() float64 {
+	return capacity.Available - capacity.Allocated
+}
+
+// WeeklyCapacity computes the capacity of every developer for every ISO week between from and to, inclusive.
+// The allocated days are taken from the attributions, so ForecastCompletion needs to have been called beforehand.
+// Capacities are sorted by developer, in the planning order, then by week.
+func WeeklyCapacity(planning *Planning, from Day, to Day) []*WeekCapacity {
+	calendar := NewCalendar(planning)
+
+	// allocated effort of each developer for each day
+	devToAllocations := make(map[DeveloperId]map[Day]float64, len(planning.Developers))
+	for _, developer := range planning.Developers {
+		devToAllocations[developer.Id] = make(map[Day]float64)
+	}
+
+	devMap := make(map[DeveloperId]*Developer, len(planning.Developers))
+	for _, developer := range planning.Developers {
+		devMap[developer.Id] = developer
+	}
+
+	walkAttributions(planning, func(project string, task *Task, devId DeveloperId, attribution *Attribution) {
+		developer, prs := devMap[devId]
+		if !prs || attribution.FirstDay == nil || attribution.LastDay == nil {
+			return
+		}
+		// the forecast spreads the effort over the working days, at the developer's utilization for the project
+		utilization := developer.projectUtilization(project, planning.RampUp)
+		remaining := float64(attribution.EffortDays)
+		for day := *attribution.FirstDay; day <= *attribution.LastDay && remaining > 0; day++ {
+			if !calendar.IsWorkingDay(devId, day) {
+				continue
+			}
+			effort := math.Min(utilization(day), remaining)
+			devToAllocations[devId][day] += effort
+			remaining -= effort
+		}
+	})
+
+	capacities := make([]*WeekCapacity, 0)
+	for _, developer := range planning.Developers {
+		var current *WeekCapacity
+		for day := from; day <= to; day++ {
+			year, week := day.ISOWeek()
+			if current == nil || current.Year != year || current.Week != week {
+				current = &WeekCapacity{
+					DevId: developer.Id,
+					Year:  year,
+					Week:  week,
+				}
+				capacities = append(capacities, current)
+			}
+
+			if calendar.IsWorkingDay(developer.Id, day) {
+				current.WorkDays++
+				current.Available += calendar.Capacity(developer.Id, day, day)
+			}
+			current.Allocated += devToAllocations[developer.Id][day]
+		}
+	}
+
+	return capacities
+}
+
+// LastPlannedDay returns the last day of all the tasks of the planning, or nil if nothing was planned
+func LastPlannedDay(planning *Planning) *Day {
+	var lastDay *Day
+	for _, task := range planning.Tasks {
+		lastDay = maxDay(lastDay, task.LastDay)
+	}
+	for _, project := range planning.Projects {
+		for _, task := range project.Tasks {
+			lastDay = maxDay(lastDay, task.LastDay)
+		}
+	}
+	return lastDay
+}
+
+// walkAttributions calls fn on every attribution of the planning, in priority order,
+// along with the project and the task it belongs to
+func walkAttributions(planning *Planning, fn func(project string, task *Task, devId DeveloperId, attribution *Attribution)) {
+	var walk func(project string, tasks []*Task)
+	walk = func(project string, tasks []*Task) {
+		for _, task := range tasks {
+			for _, devId := range task.DeveloperIds() {
+				fn(project, task, devId, task.Attributions[devId])
+			}
+			walk(project, task.Subtasks)
+		}
+	}
+
+	walk("", planning.Tasks)
+	for _, project := range planning.Projects {
+		walk(project.Name, project.Tasks)
+	}
+}
@@ -0,0 +1,92 @@
+package planner
+
+import "testing"
+
+func TestWeeklyCapacity(t *testing.T) {
+	var starts Day = 5
+	planning := &Planning{
+		StartDay: 4,
+		Holidays: []Day{6},
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 0.5, OffDays: []Day{12}},
+			{Id: "dev2", Utilization: 1, Starts: &starts},
+		},
+		SupportWeeks: []*SupportWeek{
+			{FirstDay: 11, LastDay: 11, DevId: "dev2"},
+		},
+		Tasks: []*Task{
+			{
+				Name: "task",
+				Attributions: map[DeveloperId]*Attribution{
+					"dev1": {EffortDays: 3},
+					"dev2": {EffortDays: 2},
+				},
+			},
+		},
+	}
+
+	ForecastCompletion(planning)
+
+	// week 1970-W02: 4 to 10, week 1970-W03: 11 to 17
+	// saturdays + sundays: 9, 10, 16, 17
+	// holidays: 6
+	// dev1 (3d / 0.5 = 6d): 4, 5, 7, 8, 11, 13
+	// dev2 starts on 5 (2d): 5, 7
+	capacities := WeeklyCapacity(planning, 4, 17)
+
+	exp := []WeekCapacity{
+		{DevId: "dev1", Year: 1970, Week: 2, WorkDays: 4, Available: 2, Allocated: 2},
+		{DevId: "dev1", Year: 1970, Week: 3, WorkDays: 4, Available: 2, Allocated: 1},
+		{DevId: "dev2", Year: 1970, Week: 2, WorkDays: 3, Available: 3, Allocated: 2},
+		{DevId: "dev2", Year: 1970, Week: 3, WorkDays: 4, Available: 4, Allocated: 0},
+	}
+
+	if len(capacities) != len(exp) {
+		t.Fatalf("exp %d capacities, got %d", len(exp), len(capacities))
+	}
+
+	for i, capacity := range capacities {
+		if *capacity != exp[i] {
+			t.Errorf("exp %+v, got %+v", exp[i], *capacity)
+		}
+	}
+}
+
+func TestWeeklyCapacityLeaves(t *testing.T) {
+	var leaves Day = 7
+	task1 := &Task{
+		Name:         "task1",
+		Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 2}},
+	}
+	task2 := &Task{
+		Name:         "task2",
+		Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 5}},
+	}
+	planning := &Planning{
+		StartDay:   4,
+		Developers: []*Developer{{Id: "dev1", Utilization: 1, Leaves: &leaves}},
+		Tasks:      []*Task{task1, task2},
+	}
+
+	ForecastCompletion(planning)
+
+	// dev1 leaves on 7, after working on 4, 5 for task1 and 6, 7 for task2, which cannot be completed
+	if task1.LastDay == nil || *task1.LastDay != 5 {
+		t.Errorf("exp task1 to be completed on 5, got %v", task1.LastDay)
+	}
+	if task2.LastDay != nil || task2.Attributions["dev1"].LastDay != nil {
+		t.Errorf("exp task2 not to be planned, got %v", task2.LastDay)
+	}
+
+	// the forecast and the calendar agree on the capacity of dev1, which is never exceeded
+	available := 0.0
+	for _, capacity := range WeeklyCapacity(planning, 4, 24) {
+		available += capacity.Available
+		if capacity.Free() < 0 {
+			t.Errorf("exp no more allocated than available, got %+v", *capacity)
+		}
+	}
+	if exp := NewCalendar(planning).Capacity("dev1", 4, 24); available != exp || available != 4 {
+		t.Errorf("exp a capacity of %f, got %f", exp, available)
+	}
+}
@@ -0,0 +1,101 @@
+package planner
+
+import "time"
+
+// Days are civil dates, numbered from 01/01/1970. The helpers below work on the calendar only, so that they do not
+// depend on the timezone of the machine planner runs on, nor on daylight saving time.
+
+// NewDay returns the day of a date. Months and days out of range are normalized, as with time.Date.
+func NewDay(year int, month time.Month, day int) Day {
+	m := int(month) - 1
+	year += floorDiv(m, 12)
+	m = m - floorDiv(m, 12)*12 + 1
+
+	// days from civil, see http://howardhinnant.github.io/date_algorithms.html
+	if m <= 2 {
+		year--
+	}
+	era := floorDiv(year, 400)
+	yearOfEra := year - era*400
+	shiftedMonth := m - 3
+	if m <= 2 {
+		shiftedMonth = m + 9
+	}
+	dayOfYear := (153*shiftedMonth+2)/5 + day - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+	return Day(era*146097 + dayOfEra - 719468)
+}
+
+// DayOf returns the day of the date of t, in the location of t
+func DayOf(t time.Time) Day {
+	return NewDay(t.Year(), t.Month(), t.Day())
+}
+
+// Date returns the year, month and day of the month of the day
+func (day Day) Date() (year int, month time.Month, dayOfMonth int) {
+	// civil from days, see http://howardhinnant.github.io/date_algorithms.html
+	z := int(day) + 719468
+	era := floorDiv(z, 146097)
+	dayOfEra := z - era*146097
+	yearOfEra := (dayOfEra - dayOfEra/1460 + dayOfEra/36524 - dayOfEra/146096) / 365
+	dayOfYear := dayOfEra - (365*yearOfEra + yearOfEra/4 - yearOfEra/100)
+	shiftedMonth := (5*dayOfYear + 2) / 153
+	dayOfMonth = dayOfYear - (153*shiftedMonth+2)/5 + 1
+	m := shiftedMonth + 3
+	if shiftedMonth >= 10 {
+		m = shiftedMonth - 9
+	}
+	year = yearOfEra + era*400
+	if m <= 2 {
+		year++
+	}
+	return year, time.Month(m), dayOfMonth
+}
+
+func (day Day) Year() int {
+	year, _, _ := day.Date()
+	return year
+}
+
+func (day Day) Month() time.Month {
+	_, month, _ := day.Date()
+	return month
+}
+
+func (day Day) Weekday() time.Weekday {
+	// 01/01/1970 is a Thursday
+	return time.Weekday(int(day) + 4 - floorDiv(int(day)+4, 7)*7)
+}
+
+// ISOWeek returns the ISO 8601 year and week number of the day, as time.Time.ISOWeek
+func (day Day) ISOWeek() (year int, week int) {
+	// the week belongs to the year of its Thursday
+	thursday := day - Day((int(day.Weekday())+6)%7) + 3
+	year = thursday.Year()
+	return year, int(thursday-NewDay(year, time.January, 1))/7 + 1
+}
+
+// AddDate adds years, months and days to the day, normalizing the result as time.Time.AddDate does
+func (day Day) AddDate(years int, months int, days int) Day {
+	year, month, dayOfMonth := day.Date()
+	return NewDay(year+years, month+time.Month(months), dayOfMonth+days)
+}
+
+// Time returns midnight UTC of the day
+func (day Day) Time() time.Time {
+	year, month, dayOfMonth := day.Date()
+	return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+}
+
+// Format writes the day with a time layout, such as 2006-01-02
+func (day Day) Format(layout string) string {
+	return day.Time().Format(layout)
+}
+
+func floorDiv(a int, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,50 @@
+package planner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCivilDays(t *testing.T) {
+	epoch := time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for day := Day(-800000); day <= 800000; day += 37 {
+		exp := epoch.AddDate(0, 0, int(day))
+
+		year, month, dayOfMonth := day.Date()
+		if year != exp.Year() || month != exp.Month() || dayOfMonth != exp.Day() {
+			t.Fatalf("day %d: exp %s, got %d-%d-%d", day, exp.Format("2006-01-02"), year, month, dayOfMonth)
+		}
+		if NewDay(year, month, dayOfMonth) != day {
+			t.Fatalf("day %d: NewDay(%d, %d, %d) is %d", day, year, month, dayOfMonth, NewDay(year, month, dayOfMonth))
+		}
+		if day.Weekday() != exp.Weekday() {
+			t.Fatalf("day %d: exp %s, got %s", day, exp.Weekday(), day.Weekday())
+		}
+		expYear, expWeek := exp.ISOWeek()
+		if year, week := day.ISOWeek(); year != expYear || week != expWeek {
+			t.Fatalf("day %d: exp week %d-%d, got %d-%d", day, expYear, expWeek, year, week)
+		}
+	}
+}
+
+func TestDayArithmetic(t *testing.T) {
+	// days out of range are normalized
+	if day, exp := NewDay(2021, time.February, 29), NewDay(2021, time.March, 1); day != exp {
+		t.Errorf("exp %s, got %s", DayToDate(exp), DayToDate(day))
+	}
+	if day, exp := NewDay(2021, 13, 1), NewDay(2022, time.January, 1); day != exp {
+		t.Errorf("exp %s, got %s", DayToDate(exp), DayToDate(day))
+	}
+	if day, exp := NewDay(2021, time.January, 31).AddDate(0, 1, 0), NewDay(2021, time.March, 3); day != exp {
+		t.Errorf("exp %s, got %s", DayToDate(exp), DayToDate(day))
+	}
+
+	// the day of a time is its date in its own location, whatever the local timezone
+	tokyo := time.FixedZone("Tokyo", 9*3600)
+	if day := DayOf(time.Date(2021, time.January, 1, 1, 0, 0, 0, tokyo)); day != NewDay(2021, time.January, 1) {
+		t.Errorf("exp 01/01/2021, got %s", DayToDate(day))
+	}
+	if date := NewDay(2021, time.March, 28).Format("2006/01/02"); date != "2021/03/28" {
+		t.Errorf("exp 2021/03/28, got %s", date)
+	}
+}
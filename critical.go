@@ -0,0 +1,129 @@
+package planner
+
+// CriticalStep is an attribution on the critical path, along with the availability constraints that delayed it
+type CriticalStep struct {
+	Project  string
+	Path     string
+	DevId    DeveloperId
+	FirstDay Day
+	LastDay  Day
+	// working days the developer did not work on since the previous step: holidays, off days and support weeks
+	OffDays Days
+}
+
+// AnalyzeCriticalPath finds the attributions that decide the last day of a forecasted planning, and computes the
+// slack of every attribution and task.
+// Developers work on their attributions one after the other, so each attribution is delayed by the previous
+// attributions of the same developer in the same project, and by the days they don't work. The critical path is made
+// of the chains of attributions of the developers that finish on the last day of the planning. The slack of an
+// attribution is the number of working days between the end of its chain and the last day of the planning.
+func AnalyzeCriticalPath(planning *Planning) {
+	planning.CriticalPath = nil
+	lastDay := LastPlannedDay(planning)
+	if lastDay == nil {
+		return
+	}
+
+	devMap := make(map[DeveloperId]*Developer, len(planning.Developers))
+	for _, developer := range planning.Developers {
+		devMap[developer.Id] = developer
+	}
+
+	calendar := NewCalendar(planning)
+
+	type chainKey struct {
+		project string
+		devId   DeveloperId
+	}
+	chainKeys := make([]chainKey, 0)
+	chains := make(map[chainKey][]*CriticalStep)
+	stepToAttribution := make(map[*CriticalStep]*Attribution)
+
+	walkTaskPaths(planning, func(project string, path string, task *Task) {
+		for _, devId := range task.DeveloperIds() {
+			attribution := task.Attributions[devId]
+			attribution.Slack = nil
+			attribution.Critical = false
+			if attribution.FirstDay == nil || attribution.LastDay == nil {
+				continue
+			}
+
+			key := chainKey{project, devId}
+			if _, prs := chains[key]; !prs {
+				chainKeys = append(chainKeys, key)
+			}
+			step := &CriticalStep{
+				Project:  project,
+				Path:     path,
+				DevId:    devId,
+				FirstDay: *attribution.FirstDay,
+				LastDay:  *attribution.LastDay,
+			}
+			chains[key] = append(chains[key], step)
+			stepToAttribution[step] = attribution
+		}
+	})
+
+	for _, key := range chainKeys {
+		chain := chains[key]
+		developer := devMap[key.devId]
+
+		slack := 0
+		for day := chain[len(chain)-1].LastDay + 1; day <= *lastDay; day++ {
+			if calendar.IsWorkingDay(key.devId, day) {
+				slack++
+			}
+		}
+
+		for _, step := range chain {
+			attributionSlack := slack
+			attribution := stepToAttribution[step]
+			attribution.Slack = &attributionSlack
+			attribution.Critical = slack == 0
+		}
+
+		if slack > 0 {
+			continue
+		}
+
+		previousDay := planning.StartDay - 1
+		if developer != nil && developer.Starts != nil && *developer.Starts > planning.StartDay {
+			previousDay = *developer.Starts - 1
+		}
+		for _, step := range chain {
+			step.OffDays = make(Days, 0)
+			for day := previousDay + 1; day <= step.LastDay; day++ {
+				if calendar.isOffDay(key.devId, day) {
+					step.OffDays = append(step.OffDays, day)
+				}
+			}
+			previousDay = step.LastDay
+			planning.CriticalPath = append(planning.CriticalPath, step)
+		}
+	}
+
+	var taskSlack func(tasks []*Task)
+	taskSlack = func(tasks []*Task) {
+		for _, task := range tasks {
+			task.Slack = nil
+			for _, attribution := range task.Attributions {
+				task.Slack = minSlack(task.Slack, attribution.Slack)
+			}
+			taskSlack(task.Subtasks)
+			for _, subtask := range task.Subtasks {
+				task.Slack = minSlack(task.Slack, subtask.Slack)
+			}
+		}
+	}
+	taskSlack(planning.Tasks)
+	for _, project := range planning.Projects {
+		taskSlack(project.Tasks)
+	}
+}
+
+func minSlack(a *int, b *int) *int {
+	if a == nil || (b != nil && *b < *a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,64 @@
+package planner
+
+import "testing"
+
+func TestAnalyzeCriticalPath(t *testing.T) {
+	task1 := &Task{
+		Name: "task1",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 2},
+			"dev2": {EffortDays: 1},
+		},
+	}
+	task2 := &Task{
+		Name: "task2",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 2},
+		},
+	}
+	task3 := &Task{
+		Name: "task3",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev2": {EffortDays: 1},
+		},
+	}
+	planning := &Planning{
+		StartDay: 4,
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 1, OffDays: []Day{5}},
+			{Id: "dev2", Utilization: 1},
+		},
+		Tasks: []*Task{task1, task2, task3},
+	}
+
+	ForecastCompletion(planning)
+	AnalyzeCriticalPath(planning)
+
+	// dev1 off days: 5
+	// task1:
+	// dev1 (2d): 4, 6
+	// dev2 (1d): 4
+	// task2:
+	// dev1 (2d): 7, 8
+	// task3:
+	// dev2 (1d): 5
+	// last day: 8, dev2 can slip by 6, 7, 8
+
+	if !task1.Attributions["dev1"].Critical || task1.Attributions["dev2"].Critical {
+		t.Errorf("exp only dev1 to be critical on task1")
+	}
+	if *task1.Slack != 0 || *task2.Slack != 0 || *task3.Slack != 3 {
+		t.Errorf("exp slacks 0, 0, 3, got %d, %d, %d", *task1.Slack, *task2.Slack, *task3.Slack)
+	}
+
+	if len(planning.CriticalPath) != 2 {
+		t.Fatalf("exp 2 critical steps, got %d", len(planning.CriticalPath))
+	}
+	first := planning.CriticalPath[0]
+	if first.Path != "task1" || first.DevId != "dev1" || len(first.OffDays) != 1 || first.OffDays[0] != 5 {
+		t.Errorf("exp task1 delayed by off day 5, got %+v", *first)
+	}
+	if planning.CriticalPath[1].Path != "task2" {
+		t.Errorf("exp task2 to be critical, got %s", planning.CriticalPath[1].Path)
+	}
+}
@@ -2,32 +2,66 @@ package planner
 
 import (
 	"fmt"
-	"math"
+	"strings"
 	"time"
 )
 
+// dateFormat is the layout of the dates of a planning that does not set its own
 var dateFormat = "02/01/2006"
 
+// isoDateFormat is the ISO 8601 layout, accepted for every date whatever the format of the planning
+var isoDateFormat = "2006-01-02"
+
 // from 15/02/2020 -> 18307 (nb of days since epoch)
 func DateToDay(str string) (Day, error) {
-	t, err := time.Parse(dateFormat, str)
+	return parseDate(dateFormat, str)
+}
 
+// parseDate parses a date written with the layout, or in the ISO 8601 format
+func parseDate(layout string, str string) (Day, error) {
+	t, err := time.Parse(layout, str)
 	if err != nil {
-		return 0, fmt.Errorf("error parsing date %s, should be in the format 25/05/1983", str)
+		t, err = time.Parse(isoDateFormat, str)
 	}
 
-	fromEpoch := t.Sub(time.Unix(0, 0))
+	if err != nil {
+		example := time.Date(1983, time.May, 25, 0, 0, 0, 0, time.UTC)
+		return 0, fmt.Errorf("error parsing date %s, should be in the format %s or %s",
+			str, example.Format(layout), example.Format(isoDateFormat))
+	}
 
-	return Day(math.Floor(fromEpoch.Hours() / 24.0)), nil
+	return DayOf(t), nil
+}
+
+// DateFormatLayout converts a date format made of dd, MM and yyyy, such as MM/dd/yyyy, into a time layout
+func DateFormatLayout(format string) (string, error) {
+	layout := format
+	for _, element := range []struct{ pattern, layout string }{{"yyyy", "2006"}, {"MM", "01"}, {"dd", "02"}} {
+		if strings.Count(layout, element.pattern) != 1 {
+			return "", fmt.Errorf("date format %s needs to contain %s once", format, element.pattern)
+		}
+		layout = strings.Replace(layout, element.pattern, element.layout, 1)
+	}
+
+	for _, r := range layout {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return "", fmt.Errorf("date format %s can only contain dd, MM, yyyy and separators", format)
+		}
+	}
+	return layout, nil
 }
 
 // from  18307 (nb of days since epoch) -> 15/02/2020
 func DayToDate(day Day) string {
-	t := DayToTime(day)
-	return t.Format(dateFormat)
+	return formatDate(dateFormat, day)
+}
+
+// formatDate writes the day with the layout
+func formatDate(layout string, day Day) string {
+	return day.Format(layout)
 }
 
+// DayToTime returns midnight UTC of the day
 func DayToTime(day Day) time.Time {
-	epoch := time.Unix(0, 0)
-	return epoch.Add(time.Duration(int(day)) * time.Hour * 24)
+	return day.Time()
 }
@@ -23,3 +23,56 @@ func TestDayTodate(t *testing.T) {
 		t.Errorf("exp %s, got %s", exp, act)
 	}
 }
+
+func TestISODates(t *testing.T) {
+	act, err := DateToDay("1970-01-02")
+	if err != nil {
+		t.Error(err)
+	}
+	if act != 1 {
+		t.Errorf("exp 1, got %d", act)
+	}
+}
+
+func TestDateFormatLayout(t *testing.T) {
+	layout, err := DateFormatLayout("MM/dd/yyyy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout != "01/02/2006" {
+		t.Errorf("exp 01/02/2006, got %s", layout)
+	}
+
+	for _, format := range []string{"dd/MM", "dd/MM/yy", "dd MMM yyyy"} {
+		if _, err := DateFormatLayout(format); err == nil {
+			t.Errorf("exp an error for %s", format)
+		}
+	}
+}
+
+func TestPlanningDateFormat(t *testing.T) {
+	input := PlanningInput{
+		DateFormat: "MM/dd/yyyy",
+		StartDay:   "01/05/1970",
+		Holidays:   []*DaysInput{{Date: "1970-01-06"}},
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if planning.StartDay != 4 || planning.Holidays[0] != 5 {
+		t.Errorf("exp start day 4 and holiday 5, got %d and %v", planning.StartDay, planning.Holidays)
+	}
+	if output := NewPlanningInput(planning); output.StartDay != "01/05/1970" || output.DateFormat != "MM/dd/yyyy" {
+		t.Errorf("exp the start day written as 01/05/1970, got %s", output.StartDay)
+	}
+
+	// without a format, dates are written back as ISO dates when the input uses them
+	planning, err = NewPlanning(PlanningInput{StartDay: "1970-01-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := NewPlanningInput(planning); output.StartDay != "1970-01-05" {
+		t.Errorf("exp the start day written as 1970-01-05, got %s", output.StartDay)
+	}
+}
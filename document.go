@@ -0,0 +1,678 @@
+package planner
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateYAML writes the fields computed by planner, the first and last days, slack and criticality of the tasks and
+// attributions, and the critical path, into the original YAML document of the planning. The rest of the document,
+// comments and key order included, is kept as it is, so that the output diffs cleanly against the input.
+// Tasks are matched by id, or by name when they have none, and follow the order of the planning, which the optimizer may have changed.
+func UpdateYAML(doc []byte, planning *Planning) ([]byte, error) {
+	return updateYAML(doc, NewPlanningInput(planning))
+}
+
+// UpdateIncludeYAML writes the fields computed by planner into the original YAML document of a file included by the
+// planning, as UpdateYAML does
+func UpdateIncludeYAML(doc []byte, planning *Planning, include *Include) ([]byte, error) {
+	return updateYAML(doc, &PlanningInput{Tasks: NewIncludeInput(planning, include).Tasks})
+}
+
+func updateYAML(doc []byte, output *PlanningInput) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the planning document should be a mapping")
+	}
+	mapping := root.Content[0]
+
+	if err := updateTaskNodes(mappingValue(mapping, "tasks"), output.Tasks); err != nil {
+		return nil, err
+	}
+
+	if projects := mappingValue(mapping, "projects"); projects != nil && projects.Kind == yaml.SequenceNode {
+		for _, node := range projects.Content {
+			for _, project := range output.Projects {
+				if project.Name != scalarValue(mappingValue(node, "name")) {
+					continue
+				}
+				if err := updateTaskNodes(mappingValue(node, "tasks"), project.Tasks); err != nil {
+					return nil, fmt.Errorf("error in project %s: %s", project.Name, err)
+				}
+			}
+		}
+	}
+
+	if err := setField(mapping, "criticalPath", output.CriticalPath, len(output.CriticalPath) > 0); err != nil {
+		return nil, err
+	}
+
+	// the paths of the files the planning refers to change when it is written to another directory
+	if roster := mappingValue(mapping, "roster"); roster != nil && roster.Kind == yaml.ScalarNode && output.Roster != "" {
+		roster.Value = output.Roster
+	}
+	if include := mappingValue(mapping, "include"); include != nil && include.Kind == yaml.SequenceNode && len(include.Content) == len(output.Include) {
+		for i, node := range include.Content {
+			node.Value = output.Include[i]
+		}
+	}
+	if icals := mappingValue(mapping, "icalendars"); icals != nil && icals.Kind == yaml.SequenceNode && len(icals.Content) == len(output.ICalendars) {
+		for i, node := range icals.Content {
+			if path := mappingValue(node, "path"); path != nil && path.Kind == yaml.ScalarNode {
+				path.Value = output.ICalendars[i].Path
+			}
+		}
+	}
+
+	return patchYAML(doc, &root)
+}
+
+// updateTaskNodes updates the task nodes of a sequence, and orders them like the tasks
+func updateTaskNodes(sequence *yaml.Node, tasks []*TaskInput) error {
+	if sequence == nil || sequence.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	// tasks are matched by id when they have one, and tasks sharing a name are matched in order
+	nodesByKey := make(map[string][]*yaml.Node)
+	for _, node := range sequence.Content {
+		key := taskKey(scalarValue(mappingValue(node, "id")), scalarValue(mappingValue(node, "name")))
+		nodesByKey[key] = append(nodesByKey[key], node)
+	}
+
+	content := make([]*yaml.Node, 0, len(sequence.Content))
+	for _, task := range tasks {
+		key := taskKey(task.Id, task.Name)
+		nodes := nodesByKey[key]
+		if len(nodes) == 0 {
+			return fmt.Errorf("task %s is not in the planning document", task.Name)
+		}
+		node := nodes[0]
+		nodesByKey[key] = nodes[1:]
+
+		if err := updateTaskNode(node, task); err != nil {
+			return err
+		}
+		content = append(content, node)
+	}
+
+	sequence.Content = content
+	return nil
+}
+
+func updateTaskNode(node *yaml.Node, task *TaskInput) error {
+	if err := setField(node, "firstDay", task.FirstDay, task.FirstDay != nil); err != nil {
+		return err
+	}
+	if err := setField(node, "lastDay", task.LastDay, task.LastDay != nil); err != nil {
+		return err
+	}
+	if err := setField(node, "slack", task.Slack, task.Slack != nil); err != nil {
+		return err
+	}
+
+	if attributions := mappingValue(node, "attributions"); attributions != nil && attributions.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(attributions.Content); i += 2 {
+			attribution := task.Attributions.Get(DeveloperId(attributions.Content[i].Value))
+			if attribution == nil {
+				continue
+			}
+			if err := updateAttributionNode(attributions.Content[i+1], attribution); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := updateTaskNodes(mappingValue(node, "subtasks"), task.Subtasks); err != nil {
+		return fmt.Errorf("error in subtasks of %s: %s", task.Name, err)
+	}
+	return nil
+}
+
+func updateAttributionNode(node *yaml.Node, attribution *AttributionInput) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	if err := setField(node, "firstDay", attribution.FirstDay, attribution.FirstDay != nil); err != nil {
+		return err
+	}
+	if err := setField(node, "lastDay", attribution.LastDay, attribution.LastDay != nil); err != nil {
+		return err
+	}
+	if err := setField(node, "slack", attribution.Slack, attribution.Slack != nil); err != nil {
+		return err
+	}
+	return setField(node, "critical", attribution.Critical, attribution.Critical)
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil if the node is not a mapping or has no such key
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// setField sets the value of a key of a mapping node, or removes the key when the value is not present.
+// A scalar value replaces the previous one in place, so that its style and comments are kept, and a new key is added
+// at the end of the mapping.
+func setField(node *yaml.Node, key string, value interface{}, present bool) error {
+	index := -1
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			index = i
+			break
+		}
+	}
+
+	if !present {
+		if index >= 0 {
+			node.Content = append(node.Content[:index], node.Content[index+2:]...)
+		}
+		return nil
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+	unquoteDates(&valueNode)
+
+	if index < 0 {
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		node.Content = append(node.Content, keyNode, &valueNode)
+		return nil
+	}
+
+	previous := node.Content[index+1]
+	if previous.Kind == yaml.ScalarNode && valueNode.Kind == yaml.ScalarNode {
+		previous.Value = valueNode.Value
+		previous.Tag = valueNode.Tag
+		return nil
+	}
+	valueNode.HeadComment = previous.HeadComment
+	valueNode.LineComment = previous.LineComment
+	valueNode.FootComment = previous.FootComment
+	node.Content[index+1] = &valueNode
+	return nil
+}
+
+// yamlPatch writes a YAML document updated by planner line by line: the nodes that did not change keep their original
+// lines, blank lines, comments and indentation included, and only the fields that changed are written again, with the
+// indentation of the document
+type yamlPatch struct {
+	lines []string
+	// original nodes, by position, and the value of each original key
+	original map[yamlPosition]*yaml.Node
+	values   map[*yaml.Node]*yaml.Node
+	// lines of the original pairs and sequence items, by their key or item node, from their leading comments to the
+	// blank lines and comments that follow them
+	spans map[*yaml.Node]lineSpan
+	// number of spaces nested mappings are indented by, the dash of the items of a sequence by, from the column of its
+	// key, and the content of an item by, from its dash
+	indent         int
+	sequenceIndent int
+	itemIndent     int
+}
+
+type yamlPosition struct {
+	line   int
+	column int
+	kind   yaml.Kind
+}
+
+// lineSpan is a range of lines, numbered from 1, both included
+type lineSpan struct {
+	first int
+	last  int
+}
+
+// patchYAML writes the updated root node of the document, keeping the original lines of the nodes that did not change
+func patchYAML(doc []byte, root *yaml.Node) ([]byte, error) {
+	var original yaml.Node
+	if err := yaml.Unmarshal(doc, &original); err != nil {
+		return nil, err
+	}
+	mapping := root.Content[0]
+	if !isBlock(original.Content[0]) || !isBlock(mapping) || len(original.Content[0].Content) == 0 {
+		return encodeYAML(root)
+	}
+
+	patch := &yamlPatch{
+		lines:    strings.Split(strings.TrimSuffix(string(doc), "\n"), "\n"),
+		original: make(map[yamlPosition]*yaml.Node),
+		values:   make(map[*yaml.Node]*yaml.Node),
+		spans:    make(map[*yaml.Node]lineSpan),
+	}
+	patch.index(original.Content[0], 1, len(patch.lines))
+	patch.indent, patch.sequenceIndent, patch.itemIndent = 2, 2, 2
+	patch.detectIndents(original.Content[0])
+
+	// the lines before the first key, such as a document marker, are kept as they are, and so are the blank lines and
+	// comments that end the document, after the keys added to it
+	originalKeys := original.Content[0].Content
+	first := patch.spans[originalKeys[0]].first
+	trailing := patch.trailing(lineSpan{first, len(patch.lines)}, originalKeys[len(originalKeys)-2].Line)
+	out := append([]string{}, patch.lines[:first-1]...)
+	// a document the lines cannot be kept for is written again in full
+	pairs, err := patch.pairs(mapping, trailing-1)
+	if err != nil {
+		return encodeYAML(root)
+	}
+	out = append(out, pairs...)
+	out = append(out, patch.span(lineSpan{trailing, len(patch.lines)})...)
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
+
+func isBlock(node *yaml.Node) bool {
+	return (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) && node.Style&yaml.FlowStyle == 0
+}
+
+// index records the original nodes, and the lines of the pairs and items of the block collections, within the lines
+// of their parent
+func (patch *yamlPatch) index(node *yaml.Node, first int, last int) {
+	patch.original[yamlPosition{node.Line, node.Column, node.Kind}] = node
+	if !isBlock(node) {
+		return
+	}
+
+	step := 1
+	if node.Kind == yaml.MappingNode {
+		step = 2
+	}
+	starts := make([]int, 0, len(node.Content)/step)
+	for i := 0; i < len(node.Content); i += step {
+		starts = append(starts, patch.leadingComments(node.Content[i].Line, first))
+	}
+	for i, start := range starts {
+		end := last
+		if i+1 < len(starts) {
+			end = starts[i+1] - 1
+		}
+		child := node.Content[i*step]
+		patch.spans[child] = lineSpan{start, end}
+		if step == 2 {
+			patch.original[yamlPosition{child.Line, child.Column, child.Kind}] = child
+			patch.values[child] = node.Content[i*step+1]
+			child = node.Content[i*step+1]
+		}
+		patch.index(child, start, end)
+	}
+}
+
+// leadingComments returns the first line of the comments right above the line, down to the first line given
+func (patch *yamlPatch) leadingComments(line int, first int) int {
+	for line > first && strings.HasPrefix(strings.TrimSpace(patch.lines[line-2]), "#") {
+		line--
+	}
+	return line
+}
+
+// detectIndents detects the indentation of the nested mappings and sequences of the document, from the first ones
+// that start on their own line. The default indentation is kept for the collections the document has none of.
+func (patch *yamlPatch) detectIndents(root *yaml.Node) {
+	mappings, sequences := false, false
+	var detect func(node *yaml.Node)
+	detect = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode && isBlock(node) {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if !isBlock(value) || len(value.Content) == 0 || value.Content[0].Line <= key.Line {
+					continue
+				}
+				if value.Kind == yaml.MappingNode && !mappings && value.Column > key.Column {
+					patch.indent = value.Column - key.Column
+					mappings = true
+				}
+				if value.Kind == yaml.SequenceNode && !sequences {
+					// the dash of the first item is the last one before its content
+					item := value.Content[0]
+					dash := strings.LastIndex(patch.lines[item.Line-1][:item.Column-1], "-") + 1
+					if dash >= key.Column {
+						patch.sequenceIndent = dash - key.Column
+						patch.itemIndent = item.Column - dash
+						sequences = true
+					}
+				}
+			}
+		}
+		for _, child := range node.Content {
+			if mappings && sequences {
+				return
+			}
+			detect(child)
+		}
+	}
+	detect(root)
+}
+
+func (patch *yamlPatch) originalOf(node *yaml.Node) *yaml.Node {
+	if node.Line == 0 {
+		return nil
+	}
+	return patch.original[yamlPosition{node.Line, node.Column, node.Kind}]
+}
+
+// pairs writes the pairs of a block mapping, whose original lines end at the limit
+func (patch *yamlPatch) pairs(mapping *yaml.Node, limit int) ([]string, error) {
+	column := mapping.Content[0].Column
+	out := make([]string, 0)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		lines, err := patch.pair(mapping.Content[i], mapping.Content[i+1], column, limit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, lines...)
+	}
+	return out, nil
+}
+
+func (patch *yamlPatch) pair(key *yaml.Node, value *yaml.Node, column int, limit int) ([]string, error) {
+	originalKey := patch.originalOf(key)
+	if originalKey == nil {
+		return patch.encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}, column)
+	}
+	span := patch.clip(originalKey, limit)
+	originalValue := patch.values[originalKey]
+	if equalNodes(key, originalKey) && equalNodes(value, originalValue) {
+		return patch.span(span), nil
+	}
+
+	// the key line is kept, and the children of a block collection are written one by one
+	if equalNodes(key, originalKey) && isBlock(value) && patch.originalOf(value) == originalValue && isBlock(originalValue) &&
+		len(value.Content) > 0 {
+		trailing := patch.trailing(span, key.Line)
+		out := patch.span(lineSpan{span.first, patch.spans[originalValue.Content[0]].first - 1})
+		children, err := patch.children(value, trailing-1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, children...)
+		return append(out, patch.span(lineSpan{trailing, span.last})...), nil
+	}
+
+	encoded, err := patch.encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{withoutComments(key), withoutComments(value)}}, column)
+	if err != nil {
+		return nil, err
+	}
+	return patch.surround(span, originalKey.Line, encoded), nil
+}
+
+// children writes the pairs or items of a block collection, whose original lines end at the limit
+func (patch *yamlPatch) children(node *yaml.Node, limit int) ([]string, error) {
+	if node.Kind == yaml.MappingNode {
+		return patch.pairs(node, limit)
+	}
+
+	out := make([]string, 0)
+	for _, item := range node.Content {
+		lines, err := patch.item(item, limit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, lines...)
+	}
+	return out, nil
+}
+
+// item writes an item of a block sequence, whose original lines end at the limit
+func (patch *yamlPatch) item(item *yaml.Node, limit int) ([]string, error) {
+	originalItem := patch.originalOf(item)
+	if originalItem == nil {
+		return nil, fmt.Errorf("new sequence items are not supported")
+	}
+	span := patch.clip(originalItem, limit)
+	if equalNodes(item, originalItem) {
+		return patch.span(span), nil
+	}
+
+	// the dash of the item is at the start of the line of its first key, or of its value
+	dash := item.Column - 2
+	for dash > 0 && patch.lines[item.Line-1][dash-1] != '-' {
+		dash--
+	}
+
+	if item.Kind == yaml.MappingNode && isBlock(item) && isBlock(originalItem) && len(item.Content) > 0 {
+		// the comments above the item are the ones of its first key
+		trailing := patch.trailing(span, item.Line)
+		pairs, err := patch.pairs(item, trailing-1)
+		if err != nil {
+			return nil, err
+		}
+		// the first line of the item starts with its dash, even when its first key was written again
+		for i, line := range pairs {
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				if dash > 0 && len(line) > dash && strings.TrimSpace(line[:dash+1]) == "" {
+					pairs[i] = line[:dash-1] + "- " + line[dash+1:]
+				}
+				break
+			}
+		}
+		return append(pairs, patch.span(lineSpan{trailing, span.last})...), nil
+	}
+
+	out, err := patch.encode(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{withoutComments(item)}}, dash)
+	if err != nil {
+		return nil, err
+	}
+	return patch.surround(span, item.Line, out), nil
+}
+
+// clip returns the lines of an original pair or item, up to the limit
+func (patch *yamlPatch) clip(node *yaml.Node, limit int) lineSpan {
+	span := patch.spans[node]
+	if span.last > limit {
+		span.last = limit
+	}
+	return span
+}
+
+// trailing returns the first of the blank lines and comments that end the span, after the line of its node
+func (patch *yamlPatch) trailing(span lineSpan, line int) int {
+	trailing := span.last
+	for trailing > line {
+		text := strings.TrimSpace(patch.lines[trailing-1])
+		if text != "" && !strings.HasPrefix(text, "#") {
+			break
+		}
+		trailing--
+	}
+	return trailing + 1
+}
+
+// surround adds to the lines written for a node the leading comments above its line and the blank lines and comments
+// that follow it in its span
+func (patch *yamlPatch) surround(span lineSpan, line int, lines []string) []string {
+	out := patch.span(lineSpan{span.first, line - 1})
+	out = append(out, lines...)
+	return append(out, patch.span(lineSpan{patch.trailing(span, line), span.last})...)
+}
+
+func (patch *yamlPatch) span(span lineSpan) []string {
+	if span.last < span.first {
+		return nil
+	}
+	return append([]string{}, patch.lines[span.first-1:span.last]...)
+}
+
+// encode writes the pairs of a mapping, or the items of a sequence, in block style, with the indentation of the
+// document, starting at the column
+func (patch *yamlPatch) encode(node *yaml.Node, column int) ([]string, error) {
+	unquoteDates(node)
+	if node.Kind == yaml.SequenceNode {
+		return patch.encodeItems(node, column-1)
+	}
+	return patch.encodePairs(node, column-1)
+}
+
+// encodePairs writes the pairs of a mapping, indented by the number of spaces
+func (patch *yamlPatch) encodePairs(mapping *yaml.Node, spaces int) ([]string, error) {
+	prefix := strings.Repeat(" ", spaces)
+	out := make([]string, 0)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		out = append(out, comments(key.HeadComment, prefix)...)
+		encodedKey, err := patch.encodeInline(key, spaces)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isBlock(value) || len(value.Content) == 0 {
+			lines, err := patch.encodeInline(value, spaces)
+			if err != nil {
+				return nil, err
+			}
+			lines[0] = prefix + encodedKey[0] + ": " + lines[0]
+			out = append(out, lines...)
+		} else {
+			var lines []string
+			if value.Kind == yaml.MappingNode {
+				lines, err = patch.encodePairs(value, spaces+patch.indent)
+			} else {
+				lines, err = patch.encodeItems(value, spaces+patch.sequenceIndent)
+			}
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, prefix+encodedKey[0]+":"+properties(value)+lineComment(key.LineComment))
+			out = append(out, lines...)
+		}
+		out = append(out, comments(key.FootComment, prefix)...)
+	}
+	return out, nil
+}
+
+// encodeItems writes the items of a sequence, with their dash indented by the number of spaces
+func (patch *yamlPatch) encodeItems(sequence *yaml.Node, spaces int) ([]string, error) {
+	prefix := strings.Repeat(" ", spaces)
+	out := make([]string, 0)
+	for _, item := range sequence.Content {
+		out = append(out, comments(item.HeadComment, prefix)...)
+
+		if !isBlock(item) || len(item.Content) == 0 {
+			lines, err := patch.encodeInline(item, spaces)
+			if err != nil {
+				return nil, err
+			}
+			lines[0] = prefix + "- " + lines[0]
+			out = append(out, lines...)
+			out = append(out, comments(item.FootComment, prefix)...)
+			continue
+		}
+
+		// the content of the item starts on the line of its dash, after the comments above its first key
+		var lines []string
+		var err error
+		if item.Kind == yaml.MappingNode {
+			firstKey := *item.Content[0]
+			out = append(out, comments(firstKey.HeadComment, prefix)...)
+			firstKey.HeadComment = ""
+			content := append([]*yaml.Node{&firstKey}, item.Content[1:]...)
+			lines, err = patch.encodePairs(&yaml.Node{Kind: yaml.MappingNode, Content: content}, spaces+patch.itemIndent)
+		} else {
+			lines, err = patch.encodeItems(item, spaces+patch.itemIndent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines[0] = prefix + "-" + lines[0][spaces+1:]
+		out = append(out, lines...)
+		out = append(out, comments(item.FootComment, prefix)...)
+	}
+	return out, nil
+}
+
+// encodeInline writes a scalar or a flow collection, with its line comment. The lines of a block scalar that follow
+// the first one are indented from the number of spaces.
+func (patch *yamlPatch) encodeInline(node *yaml.Node, spaces int) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(patch.indent)
+	if err := encoder.Encode(withoutComments(node)); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return lines, nil
+}
+
+// comments returns the lines of a head or foot comment, indented by the prefix
+func comments(comment string, prefix string) []string {
+	if comment == "" {
+		return nil
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return lines
+}
+
+func lineComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	return " " + comment
+}
+
+// properties returns the anchor of a block collection, written after its key
+func properties(node *yaml.Node) string {
+	if node.Anchor == "" {
+		return ""
+	}
+	return " &" + node.Anchor
+}
+
+// withoutComments returns a copy of the node without its head and foot comments, which are kept as original lines
+func withoutComments(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.HeadComment = ""
+	copied.FootComment = ""
+	return &copied
+}
+
+func equalNodes(a *yaml.Node, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Style != b.Style || a.Tag != b.Tag || a.Value != b.Value || a.Anchor != b.Anchor ||
+		a.HeadComment != b.HeadComment || a.LineComment != b.LineComment || a.FootComment != b.FootComment ||
+		len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateYAML(t *testing.T) {
+	doc := `# team planning
+startDay: 2021-01-04
+developers:
+  - id: dev1
+    offDays: [2021-01-05] # dentist
+tasks:
+  # the most urgent one
+  - name: task1
+    attributions:
+      dev1:
+        effort: 2
+        lastDay: 2020-12-01 # stale
+  - name: task2
+    attributions:
+      dev1:
+        effort: 1
+`
+	var input PlanningInput
+	if err := Unmarshal(YAML, []byte(doc), &input); err != nil {
+		t.Fatal(err)
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ForecastCompletion(planning)
+	// the tasks were reordered, as by the optimizer
+	planning.Tasks[0], planning.Tasks[1] = planning.Tasks[1], planning.Tasks[0]
+
+	out, err := UpdateYAML([]byte(doc), planning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `# team planning
+startDay: 2021-01-04
+developers:
+  - id: dev1
+    offDays: [2021-01-05] # dentist
+tasks:
+  - name: task2
+    attributions:
+      dev1:
+        effort: 1
+        firstDay: 2021-01-07
+        lastDay: 2021-01-07
+    firstDay: 2021-01-07
+    lastDay: 2021-01-07
+  # the most urgent one
+  - name: task1
+    attributions:
+      dev1:
+        effort: 2
+        lastDay: 2021-01-06 # stale
+        firstDay: 2021-01-04
+    firstDay: 2021-01-04
+    lastDay: 2021-01-06
+`
+	if string(out) != exp {
+		t.Errorf("exp\n%s\ngot\n%s", exp, out)
+	}
+
+	if _, err := UpdateYAML([]byte(strings.Replace(doc, "task2", "task3", 1)), planning); err == nil {
+		t.Errorf("exp an error for a task missing from the document")
+	}
+}
+
+func TestUpdateYAMLLayout(t *testing.T) {
+	doc := `startDay: 2021-01-04
+
+developers:
+    - id: dev1
+
+    - id: dev2
+      utilization: 0.5
+
+tasks:
+    # first
+    - name: task1
+      attributions:
+          dev1:
+              effort: 2
+
+    - name: task2
+      attributions:
+          dev2: {effort: 1}
+      subtasks:
+          - name: subtask
+
+            attributions:
+                dev1: {effort: 1}
+`
+	var input PlanningInput
+	if err := Unmarshal(YAML, []byte(doc), &input); err != nil {
+		t.Fatal(err)
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ForecastCompletion(planning)
+
+	out, err := UpdateYAML([]byte(doc), planning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// blank lines and indentation are kept, and the computed fields are indented like the document
+	exp := `startDay: 2021-01-04
+
+developers:
+    - id: dev1
+
+    - id: dev2
+      utilization: 0.5
+
+tasks:
+    # first
+    - name: task1
+      attributions:
+          dev1:
+              effort: 2
+              firstDay: 2021-01-04
+              lastDay: 2021-01-05
+      firstDay: 2021-01-04
+      lastDay: 2021-01-05
+
+    - name: task2
+      attributions:
+          dev2: {effort: 1, firstDay: 2021-01-04, lastDay: 2021-01-05}
+      subtasks:
+          - name: subtask
+
+            attributions:
+                dev1: {effort: 1, firstDay: 2021-01-06, lastDay: 2021-01-06}
+            firstDay: 2021-01-06
+            lastDay: 2021-01-06
+      firstDay: 2021-01-04
+      lastDay: 2021-01-06
+`
+	if string(out) != exp {
+		t.Errorf("exp\n%s\ngot\n%s", exp, out)
+	}
+
+	// an up to date document is written back as it is
+	again, err := UpdateYAML(out, planning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("exp the document to be unchanged, got\n%s", again)
+	}
+}
+
+func TestUpdateYAMLNewKeys(t *testing.T) {
+	doc := `startDay: 2021-01-04
+developers:
+    -   id: dev1
+        offDays:
+            - 2021-01-05
+tasks:
+    -   name: task1
+        attributions:
+            dev1:
+                effort: 2
+
+# the end
+`
+	var input PlanningInput
+	if err := Unmarshal(YAML, []byte(doc), &input); err != nil {
+		t.Fatal(err)
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ForecastCompletion(planning)
+	AnalyzeCriticalPath(planning)
+
+	out, err := UpdateYAML([]byte(doc), planning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// new keys come before the comments that end the document, and nested collections are indented like the document
+	exp := `startDay: 2021-01-04
+developers:
+    -   id: dev1
+        offDays:
+            - 2021-01-05
+tasks:
+    -   name: task1
+        attributions:
+            dev1:
+                effort: 2
+                firstDay: 2021-01-04
+                lastDay: 2021-01-06
+                slack: 0
+                critical: true
+        firstDay: 2021-01-04
+        lastDay: 2021-01-06
+        slack: 0
+criticalPath:
+    -   task: task1
+        devId: dev1
+        firstDay: 2021-01-04
+        lastDay: 2021-01-06
+        offDays:
+            - 2021-01-05
+
+# the end
+`
+	if string(out) != exp {
+		t.Errorf("exp\n%s\ngot\n%s", exp, out)
+	}
+}
@@ -0,0 +1,401 @@
+package planner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formats of the planning files. They all have the same fields, with the same names.
+const (
+	YAML = "yaml"
+	JSON = "json"
+	TOML = "toml"
+)
+
+// FormatOf detects the format of a planning file from its extension, YAML by default
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return JSON
+	case ".toml":
+		return TOML
+	default:
+		return YAML
+	}
+}
+
+// Unmarshal decodes a planning, roster or overlay file in the given format
+func Unmarshal(format string, dat []byte, v interface{}) error {
+	switch format {
+	case YAML:
+		return yaml.Unmarshal(dat, v)
+	case JSON:
+		return json.Unmarshal(dat, v)
+	case TOML:
+		metaData, err := toml.Decode(string(dat), v)
+		if err != nil {
+			return err
+		}
+		orderAttributions(v, metaData)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// orderAttributions orders the attributions of the tasks of a TOML file as in the file. TOML tables are decoded into
+// maps, so the order is rebuilt from the keys of the file, which are listed in document order.
+func orderAttributions(v interface{}, metaData toml.MetaData) {
+	orders := tomlAttributionOrders(metaData)
+
+	var orderTasks func(path string, tasks []*TaskInput)
+	orderTasks = func(path string, tasks []*TaskInput) {
+		for i, task := range tasks {
+			taskPath := fmt.Sprintf("%s[%d]", path, i)
+			if len(task.Attributions) > 0 {
+				task.Attributions.order(orders[taskPath])
+			}
+			orderTasks(taskPath+".subtasks", task.Subtasks)
+		}
+	}
+
+	switch v := v.(type) {
+	case *PlanningInput:
+		orderTasks("tasks", v.Tasks)
+		for i, project := range v.Projects {
+			orderTasks(fmt.Sprintf("projects[%d].tasks", i), project.Tasks)
+		}
+	case *IncludeInput:
+		orderTasks("tasks", v.Tasks)
+	}
+}
+
+// tomlAttributionOrders lists the developers of the attributions of each task of a TOML file in document order, by
+// path of the task, such as tasks[0].subtasks[1]. The keys of the file carry no array index, so the elements of an
+// array are told apart by their [[header]], or, in an inline array, by a key of the element that comes again.
+func tomlAttributionOrders(metaData toml.MetaData) map[string][]DeveloperId {
+	// index of the current element of each array, and the keys of this element, by path of the array in the file
+	indexes := make(map[string]int)
+	elementKeys := make(map[string]map[string]bool)
+	newElement := func(array string, index int) {
+		indexes[array] = index
+		elementKeys[array] = make(map[string]bool)
+		// the arrays of the previous element are done
+		for path := range indexes {
+			if strings.HasPrefix(path, array+".") {
+				delete(indexes, path)
+				delete(elementKeys, path)
+			}
+		}
+	}
+	// elementPath adds the index of the current element to the arrays of the key path
+	elementPath := func(key toml.Key) string {
+		path := ""
+		for i := range key {
+			if i > 0 {
+				path += "."
+			}
+			path += key[i]
+			if index, prs := indexes[strings.Join(key[:i+1], ".")]; prs {
+				path += fmt.Sprintf("[%d]", index)
+			}
+		}
+		return path
+	}
+
+	orders := make(map[string][]DeveloperId)
+	ordered := make(map[string]bool)
+	for _, key := range metaData.Keys() {
+		parent := strings.Join(key[:len(key)-1], ".")
+		typ := metaData.Type(key...)
+		if keys, prs := elementKeys[parent]; prs && typ != "ArrayHash" {
+			if keys[key[len(key)-1]] {
+				newElement(parent, indexes[parent]+1)
+			}
+			elementKeys[parent][key[len(key)-1]] = true
+		}
+
+		path := strings.Join(key, ".")
+		switch typ {
+		case "ArrayHash":
+			index := 0
+			if previous, prs := indexes[path]; prs {
+				index = previous + 1
+			}
+			newElement(path, index)
+		case "Array":
+			newElement(path, 0)
+		}
+
+		// the developer of an attribution is the key that follows the attributions of a task
+		for i := 1; i+1 < len(key); i++ {
+			if _, isTask := indexes[strings.Join(key[:i], ".")]; !isTask || key[i] != "attributions" {
+				continue
+			}
+			task := elementPath(key[:i])
+			devId := DeveloperId(key[i+1])
+			if !ordered[task+"."+string(devId)] {
+				ordered[task+"."+string(devId)] = true
+				orders[task] = append(orders[task], devId)
+			}
+		}
+	}
+	return orders
+}
+
+// Marshal encodes a planning in the given format, as a text file ending with a newline
+func Marshal(format string, v interface{}) ([]byte, error) {
+	switch format {
+	case YAML:
+		var node yaml.Node
+		if err := node.Encode(v); err != nil {
+			return nil, err
+		}
+		return encodeYAML(&node)
+	case JSON:
+		doc, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(doc, '\n'), nil
+	case TOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// encodeYAML writes a YAML document tree with the indentation of the examples, and ISO dates left unquoted
+func encodeYAML(node *yaml.Node) ([]byte, error) {
+	unquoteDates(node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unquoteDates clears the quotes the encoder puts around the strings that YAML would otherwise read as timestamps.
+// Planning dates are always read as strings, and are written the way they are usually written by hand.
+func unquoteDates(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && node.Style == yaml.DoubleQuotedStyle {
+		if _, err := time.Parse(isoDateFormat, node.Value); err == nil {
+			node.Tag = ""
+			node.Style = 0
+		}
+	}
+	for _, child := range node.Content {
+		unquoteDates(child)
+	}
+}
+
+// UnmarshalYAML decodes the attributions of a task in the order of the file
+func (attributions *AttributionInputs) UnmarshalYAML(node *yaml.Node) error {
+	// type errors are returned as such, so that the decoder goes on with the other fields
+	if node.Kind != yaml.MappingNode {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: attributions should be a mapping of developer ids to attributions", node.Line)}}
+	}
+	*attributions = nil
+	typeErr := &yaml.TypeError{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var attribution AttributionInput
+		if err := node.Content[i+1].Decode(&attribution); err != nil {
+			nodeErr, ok := err.(*yaml.TypeError)
+			if !ok {
+				return err
+			}
+			typeErr.Errors = append(typeErr.Errors, nodeErr.Errors...)
+		}
+		*attributions = append(*attributions, &DeveloperAttributionInput{
+			DevId:       DeveloperId(node.Content[i].Value),
+			Attribution: &attribution,
+		})
+	}
+	if len(typeErr.Errors) > 0 {
+		return typeErr
+	}
+	return nil
+}
+
+// MarshalYAML encodes the attributions of a task in order, rather than sorted by developer id
+func (attributions AttributionInputs) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, attribution := range attributions {
+		var value yaml.Node
+		if err := value.Encode(attribution.Attribution); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: string(attribution.DevId)}, &value)
+	}
+	return node, nil
+}
+
+// UnmarshalJSON decodes the attributions of a task in the order of the file
+func (attributions *AttributionInputs) UnmarshalJSON(dat []byte) error {
+	members, err := jsonMembers(dat)
+	if err != nil {
+		return err
+	}
+	// encoding/json stops at the errors of the types that decode themselves, while it goes on after the type errors
+	// of the other fields. Type errors are left to checkSource, which reports them with their position.
+	*attributions = nil
+	for _, member := range members {
+		var attribution AttributionInput
+		if err := json.Unmarshal(member.value, &attribution); err != nil {
+			if _, ok := err.(*json.UnmarshalTypeError); !ok {
+				return err
+			}
+		}
+		*attributions = append(*attributions, &DeveloperAttributionInput{
+			DevId:       DeveloperId(member.key),
+			Attribution: &attribution,
+		})
+	}
+	return nil
+}
+
+// MarshalJSON encodes the attributions of a task in order, rather than sorted by developer id
+func (attributions AttributionInputs) MarshalJSON() ([]byte, error) {
+	members := make([]*jsonMember, len(attributions))
+	for i, attribution := range attributions {
+		value, err := json.Marshal(attribution.Attribution)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = &jsonMember{key: string(attribution.DevId), value: value}
+	}
+	return jsonObject(members)
+}
+
+// UnmarshalTOML decodes the attributions of a task, sorted by developer id: TOML tables are decoded into maps, so
+// orderAttributions orders them as in the file afterwards
+func (attributions *AttributionInputs) UnmarshalTOML(data interface{}) error {
+	table, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%v should be a table of developer ids to attributions", data)
+	}
+	*attributions = nil
+	for _, devId := range sortedKeys(table) {
+		// the fields have the same names in JSON
+		dat, err := json.Marshal(table[devId])
+		if err != nil {
+			return err
+		}
+		var attribution AttributionInput
+		if err := json.Unmarshal(dat, &attribution); err != nil {
+			return err
+		}
+		*attributions = append(*attributions, &DeveloperAttributionInput{
+			DevId:       DeveloperId(devId),
+			Attribution: &attribution,
+		})
+	}
+	return nil
+}
+
+// MarshalTOML writes the attributions of a task in order, as an inline table, since the tables of the encoder are
+// sorted by key
+func (attributions AttributionInputs) MarshalTOML() ([]byte, error) {
+	fields := make([]string, len(attributions))
+	for i, attribution := range attributions {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(attribution.Attribution); err != nil {
+			return nil, err
+		}
+		// the encoder writes one key per line, in the order of the fields
+		values := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		fields[i] = fmt.Sprintf("%s = { %s }", tomlKey(string(attribution.DevId)), strings.Join(values, ", "))
+	}
+	return []byte("{ " + strings.Join(fields, ", ") + " }"), nil
+}
+
+// sortedKeys returns the keys of a TOML table, sorted
+func sortedKeys(table map[string]interface{}) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var tomlBareKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey writes a TOML key, quoted unless it is a bare key
+func tomlKey(key string) string {
+	if tomlBareKeyRegexp.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// jsonMember is a key and its raw value in a JSON object
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// jsonMembers returns the members of a JSON object in order, or nil if the value is not an object
+func jsonMembers(dat []byte) ([]*jsonMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(dat))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, nil
+	}
+
+	members := make([]*jsonMember, 0)
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		member := &jsonMember{key: key.(string)}
+		if err := decoder.Decode(&member.value); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// jsonObject writes the members of a JSON object in order
+func jsonObject(members []*jsonMember) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(member.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
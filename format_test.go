@@ -0,0 +1,186 @@
+package planner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	docs := map[string]string{
+		YAML: `
+startDay: 01/01/1970
+supportWeeks:
+  - firstDay: 05/01/1970
+    lastDay: 09/01/1970
+    devId: dev1
+developers:
+  - id: dev1
+    offDays:
+      - 02/01/1970
+      - from: 12/01/1970
+        to: 13/01/1970
+        type: training
+tasks:
+  - name: task1
+    attributions:
+      dev1:
+        effort: 2
+`,
+		JSON: `{
+  "startDay": "01/01/1970",
+  "supportWeeks": [{"firstDay": "05/01/1970", "lastDay": "09/01/1970", "devId": "dev1"}],
+  "developers": [
+    {"id": "dev1", "offDays": ["02/01/1970", {"from": "12/01/1970", "to": "13/01/1970", "type": "training"}]}
+  ],
+  "tasks": [{"name": "task1", "attributions": {"dev1": {"effort": 2}}}]
+}`,
+		TOML: `
+startDay = "01/01/1970"
+
+[[supportWeeks]]
+firstDay = "05/01/1970"
+lastDay = "09/01/1970"
+devId = "dev1"
+
+[[developers]]
+id = "dev1"
+offDays = ["02/01/1970", { from = "12/01/1970", to = "13/01/1970", type = "training" }]
+
+[[tasks]]
+name = "task1"
+attributions.dev1.effort = 2
+`,
+	}
+
+	var exp PlanningInput
+	if err := Unmarshal(YAML, []byte(docs[YAML]), &exp); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{JSON, TOML} {
+		var input PlanningInput
+		if err := Unmarshal(format, []byte(docs[format]), &input); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if !reflect.DeepEqual(input, exp) {
+			t.Errorf("%s: exp %+v, got %+v", format, exp, input)
+		}
+	}
+
+	// each format reads what it writes
+	for _, format := range []string{YAML, JSON, TOML} {
+		dat, err := Marshal(format, &exp)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		var input PlanningInput
+		if err := Unmarshal(format, dat, &input); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if !reflect.DeepEqual(input, exp) {
+			t.Errorf("%s: exp %+v, got %+v", format, exp, input)
+		}
+	}
+
+	if format := FormatOf("planning.TOML"); format != TOML {
+		t.Errorf("exp toml, got %s", format)
+	}
+	if format := FormatOf("planning.yml"); format != YAML {
+		t.Errorf("exp yaml, got %s", format)
+	}
+}
+
+func TestAttributionOrder(t *testing.T) {
+	doc := `
+startDay: 01/01/1970
+developers:
+  - id: dev1
+  - id: dev2
+  - id: dev3
+tasks:
+  - name: task1
+    attributions:
+      dev3:
+        effort: 1
+      dev1:
+        effort: 1
+      dev2:
+        effort: 1
+`
+	var input PlanningInput
+	if err := Unmarshal(YAML, []byte(doc), &input); err != nil {
+		t.Fatal(err)
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []DeveloperId{"dev3", "dev1", "dev2"}
+	if devIds := planning.Tasks[0].DeveloperIds(); !reflect.DeepEqual(devIds, exp) {
+		t.Errorf("exp %v, got %v", exp, devIds)
+	}
+
+	for _, format := range []string{YAML, JSON, TOML} {
+		dat, err := Marshal(format, NewPlanningInput(planning))
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		var output PlanningInput
+		if err := Unmarshal(format, dat, &output); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if devIds := output.Tasks[0].Attributions.developerIds(); !reflect.DeepEqual(devIds, exp) {
+			t.Errorf("%s: exp %v, got %v", format, exp, devIds)
+		}
+	}
+
+	// TOML attributions keep the order of the file too, whether the tasks are tables or inline tables
+	tomlDoc := `
+startDay = "01/01/1970"
+
+[[tasks]]
+name = "task1"
+[tasks.attributions.dev3]
+effort = 1
+[tasks.attributions.dev1]
+effort = 1
+
+[[tasks.subtasks]]
+name = "subtask1"
+attributions = { dev2 = { effort = 1 }, dev1 = { effort = 1 } }
+
+[[tasks.subtasks]]
+name = "subtask2"
+attributions.dev3.effort = 1
+attributions.dev2.effort = 1
+
+[[tasks]]
+name = "task2"
+attributions = { dev2 = { effort = 1 }, dev3 = { effort = 1 } }
+
+[[projects]]
+name = "project"
+tasks = [
+  { name = "task3", attributions = { dev3 = { effort = 1 }, dev2 = { effort = 1 } } },
+  { name = "task4", attributions = { dev1 = { effort = 1 }, dev3 = { effort = 1 } } },
+]
+`
+	var tomlInput PlanningInput
+	if err := Unmarshal(TOML, []byte(tomlDoc), &tomlInput); err != nil {
+		t.Fatal(err)
+	}
+	tomlExp := map[*TaskInput][]DeveloperId{
+		tomlInput.Tasks[0]:             {"dev3", "dev1"},
+		tomlInput.Tasks[0].Subtasks[0]: {"dev2", "dev1"},
+		tomlInput.Tasks[0].Subtasks[1]: {"dev3", "dev2"},
+		tomlInput.Tasks[1]:             {"dev2", "dev3"},
+		tomlInput.Projects[0].Tasks[0]: {"dev3", "dev2"},
+		tomlInput.Projects[0].Tasks[1]: {"dev1", "dev3"},
+	}
+	for task, exp := range tomlExp {
+		if devIds := task.Attributions.developerIds(); !reflect.DeepEqual(devIds, exp) {
+			t.Errorf("toml: exp %v for %s, got %v", exp, task.Name, devIds)
+		}
+	}
+}
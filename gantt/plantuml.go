@@ -6,7 +6,6 @@ import (
 	"io"
 	"log"
 	"sort"
-	"time"
 )
 
 type Color string
@@ -20,6 +19,12 @@ var colors = []Color{
 	"LightGray",
 }
 
+// summaryColor is used for the bars of tasks that are broken down in subtasks
+var summaryColor Color = "DarkGray"
+
+// criticalColor is used for the bars of the attributions on the critical path
+var criticalColor Color = "Red"
+
 type drawer struct {
 	devToColor map[planner.DeveloperId]Color
 }
@@ -29,13 +34,22 @@ func newDrawer(devToColor map[planner.DeveloperId]Color) *drawer {
 }
 
 func (g *drawer) drawLine(firstDay planner.Day, lastDay planner.Day, name string, developerId planner.DeveloperId) string {
+	return g.drawColoredLine(firstDay, lastDay, name, developerId, g.devToColor[developerId])
+}
+
+func (g *drawer) drawColoredLine(firstDay planner.Day, lastDay planner.Day, name string, developerId planner.DeveloperId, color Color) string {
 	firstDayDate := dayToPlantUMLDate(firstDay)
 	lastDayDate := dayToPlantUMLDate(lastDay)
-	color := g.devToColor[developerId]
 	line := fmt.Sprintf("[<font:sans>%s (%s)] is colored in %s and starts on %s and ends on %s\n", name, developerId, color, firstDayDate, lastDayDate)
 	return line
 }
 
+func (g *drawer) drawSummary(firstDay planner.Day, lastDay planner.Day, name string) string {
+	firstDayDate := dayToPlantUMLDate(firstDay)
+	lastDayDate := dayToPlantUMLDate(lastDay)
+	return fmt.Sprintf("[<font:sans>%s] is colored in %s and starts on %s and ends on %s\n", name, summaryColor, firstDayDate, lastDayDate)
+}
+
 func (g *drawer) drawMilestone(day planner.Day, name string) string {
 	return fmt.Sprintf("[<font:sans>%s] happens on %s\n", name, dayToPlantUMLDate(day))
 }
@@ -86,6 +100,9 @@ func (writer *writer) closedDays() {
 
 	for _, holiday := range writer.planning.Holidays {
 		writer.writeStr(fmt.Sprintf("%s is closed\n", dayToPlantUMLDate(holiday)))
+		if name, prs := writer.planning.HolidayName("", holiday); prs {
+			writer.writeStr(fmt.Sprintf("%s is named [%s]\n", dayToPlantUMLDate(holiday), name))
+		}
 	}
 }
 
@@ -95,19 +112,47 @@ func (writer *writer) projectStart() {
 }
 
 func (writer *writer) tasks() {
-	writer.section("Roadmap")
-	for _, task := range writer.planning.Tasks {
-		for developerId, attribution := range task.Attributions {
+	if len(writer.planning.Tasks) > 0 || len(writer.planning.Projects) == 0 {
+		writer.section("Roadmap")
+		writer.taskTree(writer.planning.Tasks, "")
+	}
+	for _, project := range writer.planning.Projects {
+		writer.section(project.Name)
+		writer.taskTree(project.Tasks, project.Name+" / ")
+	}
+}
+
+// taskTree draws the tasks in tree order. Subtasks are prefixed with the names of their parents,
+// so that the hierarchy is visible and that bar names stay unique.
+func (writer *writer) taskTree(tasks []*planner.Task, prefix string) {
+	for _, task := range tasks {
+		name := prefix + task.Name
+		if len(task.Subtasks) > 0 && task.FirstDay != nil && task.LastDay != nil {
+			summary := writer.drawer.drawSummary(*task.FirstDay, *task.LastDay, name)
+			writer.writeStr(summary)
+		}
+
+		for _, developerId := range task.DeveloperIds() {
+			attribution := task.Attributions[developerId]
 			firstDay := attribution.FirstDay
 			lastDay := attribution.LastDay
 			if firstDay == nil || lastDay == nil {
 				continue
 			}
 
-			line := writer.drawer.drawLine(*firstDay, *lastDay, task.Name, developerId)
+			line := writer.drawer.drawLine(*firstDay, *lastDay, name, developerId)
+			if attribution.Critical {
+				line = writer.drawer.drawColoredLine(*firstDay, *lastDay, name, developerId, criticalColor)
+			}
 			writer.writeStr(line)
 		}
-		milestone := writer.drawer.drawMilestone(*task.LastDay, fmt.Sprintf("%s completed", task.Name))
+
+		writer.taskTree(task.Subtasks, name+" / ")
+
+		if task.LastDay == nil {
+			continue
+		}
+		milestone := writer.drawer.drawMilestone(*task.LastDay, fmt.Sprintf("%s completed", name))
 		writer.writeStr(milestone)
 	}
 }
@@ -135,55 +180,95 @@ func (writer *writer) offdays() {
 		}
 
 		// vacations
-		// find contiguous days and make a line out of them
-
 		days := developer.OffDays
 		sort.Sort(days)
-		var firstDay *planner.Day
-		var lastDay *planner.Day
-
-		i := 0
-		for _, day := range days {
-			d := day
-			if firstDay == nil {
-				firstDay = &d
-				lastDay = &d
-				continue
-			}
+		writer.dayRanges(days, string(developer.Id), developer.Id, func(day planner.Day) string {
+			return offDayLabel(developer.OffDayReasons[day])
+		})
+
+		// holidays of the developer's calendars
+		for _, calendar := range developer.Calendars {
+			days := append(planner.Days{}, writer.planning.Calendars[calendar]...)
+			sort.Sort(days)
+			writer.dayRanges(days, fmt.Sprintf("%s %s holidays", developer.Id, calendar), developer.Id, func(day planner.Day) string {
+				name, _ := writer.planning.HolidayName(calendar, day)
+				return name
+			})
+		}
 
-			if lastDay == nil {
-				log.Fatalf("Unreachable code")
-			}
+		// end
+		if developer.Leaves != nil {
+			ms := writer.drawer.drawMilestone(*developer.Leaves, fmt.Sprintf("%s leaves", developer.Id))
+			writer.writeStr(ms)
+		}
+	}
+}
 
-			if int(d) == int(*lastDay) + 1 {
-				lastDay = &d
-				continue
-			}
+// offDayLabel describes the reason of an off day, or is empty when it has none
+func offDayLabel(reason planner.OffDayReason) string {
+	switch {
+	case reason.Name != "" && reason.Type != "":
+		return fmt.Sprintf("%s: %s", reason.Type, reason.Name)
+	case reason.Name != "":
+		return reason.Name
+	default:
+		return string(reason.Type)
+	}
+}
 
+// dayRanges finds contiguous days with the same label in the sorted days and makes a line out of each of them.
+// Lines are named after their label, or numbered when they have none.
+func (writer *writer) dayRanges(days planner.Days, name string, developerId planner.DeveloperId, label func(planner.Day) string) {
+	var firstDay *planner.Day
+	var lastDay *planner.Day
+
+	i := 0
+	labelCounts := map[string]int{}
+	drawRange := func() {
+		var lineName string
+		if l := label(*firstDay); l == "" {
 			i++
-			line := writer.drawer.drawLine(*firstDay, *lastDay, fmt.Sprintf("%s - %d", developer.Id, i), developer.Id)
-			writer.writeStr(line)
+			lineName = fmt.Sprintf("%s - %d", name, i)
+		} else {
+			// bars are identified by their name, so the ones sharing a label are numbered
+			labelCounts[l]++
+			lineName = fmt.Sprintf("%s - %s", name, l)
+			if labelCounts[l] > 1 {
+				lineName = fmt.Sprintf("%s #%d", lineName, labelCounts[l])
+			}
+		}
+		line := writer.drawer.drawLine(*firstDay, *lastDay, lineName, developerId)
+		writer.writeStr(line)
+	}
+
+	for _, day := range days {
+		d := day
+		if firstDay == nil {
 			firstDay = &d
 			lastDay = &d
+			continue
 		}
 
-		if firstDay != nil && lastDay != nil {
-			i++
-			line := writer.drawer.drawLine(*firstDay, *lastDay, fmt.Sprintf("%s - %d", developer.Id, i), developer.Id)
-			writer.writeStr(line)
+		if lastDay == nil {
+			log.Fatalf("Unreachable code")
 		}
 
-		// end
-		if developer.Leaves != nil {
-			ms := writer.drawer.drawMilestone(*developer.Leaves, fmt.Sprintf("%s leaves", developer.Id))
-			writer.writeStr(ms)
+		if int(d) == int(*lastDay) + 1 && label(d) == label(*lastDay) {
+			lastDay = &d
+			continue
 		}
+
+		drawRange()
+		firstDay = &d
+		lastDay = &d
+	}
+
+	if firstDay != nil && lastDay != nil {
+		drawRange()
 	}
 }
 
 // from  18307 (nb of days since epoch) -> 15/02/2020
 func dayToPlantUMLDate(day planner.Day) string {
-	epoch := time.Unix(0, 0)
-	t := epoch.Add(time.Duration(int(day)) * time.Hour * 24)
-	return t.Format("2006/01/02")
+	return day.Format("2006/01/02")
 }
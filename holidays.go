@@ -0,0 +1,270 @@
+package planner
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PublicHoliday is a holiday generated from the public holiday rules of a country or region
+type PublicHoliday struct {
+	Day  Day
+	Name string
+}
+
+// holidayRule computes the day of a public holiday for a given year, if it is a holiday that year
+type holidayRule struct {
+	name string
+	// the first year the holiday exists, 0 if it always existed
+	since int
+	day   func(year int) Day
+}
+
+// fixed is a holiday on the same date every year
+func fixed(month time.Month, day int, name string) holidayRule {
+	return holidayRule{name: name, day: func(year int) Day {
+		return NewDay(year, month, day)
+	}}
+}
+
+// easter is a holiday a number of days after Easter Sunday
+func easter(offset int, name string) holidayRule {
+	return holidayRule{name: name, day: func(year int) Day {
+		return easterSunday(year) + Day(offset)
+	}}
+}
+
+// nthWeekday is a holiday on the nth weekday of a month, or on the last one if n is -1
+func nthWeekday(n int, weekday time.Weekday, month time.Month, name string) holidayRule {
+	return holidayRule{name: name, day: func(year int) Day {
+		if n < 0 {
+			last := NewDay(year, month+1, 1) - 1
+			return last - Day((int(last.Weekday())-int(weekday)+7)%7)
+		}
+		first := NewDay(year, month, 1)
+		return first + Day((int(weekday)-int(first.Weekday())+7)%7) + Day(7*(n-1))
+	}}
+}
+
+func since(year int, rule holidayRule) holidayRule {
+	rule.since = year
+	return rule
+}
+
+var germanHolidays = []holidayRule{
+	fixed(time.January, 1, "New Year's Day"),
+	easter(-2, "Good Friday"),
+	easter(1, "Easter Monday"),
+	fixed(time.May, 1, "Labour Day"),
+	easter(39, "Ascension Day"),
+	easter(50, "Whit Monday"),
+	fixed(time.October, 3, "German Unity Day"),
+	fixed(time.December, 25, "Christmas Day"),
+	fixed(time.December, 26, "Boxing Day"),
+}
+
+// holidayRules are the public holidays by ISO 3166 country or subdivision code. Holidays moved when they fall on a
+// weekend are not supported.
+var holidayRules = map[string][]holidayRule{
+	"BE": {
+		fixed(time.January, 1, "New Year's Day"),
+		easter(1, "Easter Monday"),
+		fixed(time.May, 1, "Labour Day"),
+		easter(39, "Ascension Day"),
+		easter(50, "Whit Monday"),
+		fixed(time.July, 21, "National Day"),
+		fixed(time.August, 15, "Assumption Day"),
+		fixed(time.November, 1, "All Saints' Day"),
+		fixed(time.November, 11, "Armistice Day"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+	"DE": germanHolidays,
+	"DE-BE": append([]holidayRule{
+		since(2019, fixed(time.March, 8, "International Women's Day")),
+	}, germanHolidays...),
+	"DE-BW": append([]holidayRule{
+		fixed(time.January, 6, "Epiphany"),
+		easter(60, "Corpus Christi"),
+		fixed(time.November, 1, "All Saints' Day"),
+	}, germanHolidays...),
+	"DE-BY": append([]holidayRule{
+		fixed(time.January, 6, "Epiphany"),
+		easter(60, "Corpus Christi"),
+		fixed(time.August, 15, "Assumption Day"),
+		fixed(time.November, 1, "All Saints' Day"),
+	}, germanHolidays...),
+	"ES": {
+		fixed(time.January, 1, "New Year's Day"),
+		fixed(time.January, 6, "Epiphany"),
+		easter(-2, "Good Friday"),
+		fixed(time.May, 1, "Labour Day"),
+		fixed(time.August, 15, "Assumption Day"),
+		fixed(time.October, 12, "National Day"),
+		fixed(time.November, 1, "All Saints' Day"),
+		fixed(time.December, 6, "Constitution Day"),
+		fixed(time.December, 8, "Immaculate Conception"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+	"FR": {
+		fixed(time.January, 1, "New Year's Day"),
+		easter(1, "Easter Monday"),
+		fixed(time.May, 1, "Labour Day"),
+		fixed(time.May, 8, "Victory in Europe Day"),
+		easter(39, "Ascension Day"),
+		easter(50, "Whit Monday"),
+		fixed(time.July, 14, "Bastille Day"),
+		fixed(time.August, 15, "Assumption Day"),
+		fixed(time.November, 1, "All Saints' Day"),
+		fixed(time.November, 11, "Armistice Day"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+	"GB-ENG": {
+		fixed(time.January, 1, "New Year's Day"),
+		easter(-2, "Good Friday"),
+		easter(1, "Easter Monday"),
+		nthWeekday(1, time.Monday, time.May, "Early May Bank Holiday"),
+		nthWeekday(-1, time.Monday, time.May, "Spring Bank Holiday"),
+		nthWeekday(-1, time.Monday, time.August, "Summer Bank Holiday"),
+		fixed(time.December, 25, "Christmas Day"),
+		fixed(time.December, 26, "Boxing Day"),
+	},
+	"IT": {
+		fixed(time.January, 1, "New Year's Day"),
+		fixed(time.January, 6, "Epiphany"),
+		easter(1, "Easter Monday"),
+		fixed(time.April, 25, "Liberation Day"),
+		fixed(time.May, 1, "Labour Day"),
+		fixed(time.June, 2, "Republic Day"),
+		fixed(time.August, 15, "Assumption Day"),
+		fixed(time.November, 1, "All Saints' Day"),
+		fixed(time.December, 8, "Immaculate Conception"),
+		fixed(time.December, 25, "Christmas Day"),
+		fixed(time.December, 26, "St. Stephen's Day"),
+	},
+	"US": {
+		fixed(time.January, 1, "New Year's Day"),
+		nthWeekday(3, time.Monday, time.January, "Martin Luther King Jr. Day"),
+		nthWeekday(3, time.Monday, time.February, "Washington's Birthday"),
+		nthWeekday(-1, time.Monday, time.May, "Memorial Day"),
+		since(2021, fixed(time.June, 19, "Juneteenth")),
+		fixed(time.July, 4, "Independence Day"),
+		nthWeekday(1, time.Monday, time.September, "Labor Day"),
+		nthWeekday(2, time.Monday, time.October, "Columbus Day"),
+		fixed(time.November, 11, "Veterans Day"),
+		nthWeekday(4, time.Thursday, time.November, "Thanksgiving Day"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+}
+
+// PublicHolidays generates the public holidays of the given countries or regions, from the first to the last year
+// included. Holidays shared by several regions are only generated once, and they are sorted by day.
+func PublicHolidays(regions []string, firstYear int, lastYear int) ([]*PublicHoliday, error) {
+	holidays := make([]*PublicHoliday, 0)
+	seen := make(map[Day]bool)
+	for _, region := range regions {
+		rules, prs := holidayRules[region]
+		if !prs {
+			return nil, fmt.Errorf("unknown holiday rules %s, known ones are %v", region, knownHolidayRules())
+		}
+
+		for year := firstYear; year <= lastYear; year++ {
+			for _, rule := range rules {
+				if year < rule.since {
+					continue
+				}
+				day := rule.day(year)
+				if seen[day] {
+					continue
+				}
+				seen[day] = true
+				holidays = append(holidays, &PublicHoliday{Day: day, Name: rule.name})
+			}
+		}
+	}
+
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Day < holidays[j].Day
+	})
+	return holidays, nil
+}
+
+// extendPublicHolidays adds the public holidays of the holiday rules of the planning to its holidays, up to the year of
+// the given day. They are generated year by year, as far as the planning needs them, rather than for a fixed number of
+// years. It tells whether holidays were added.
+func (planning *Planning) extendPublicHolidays(lastDay Day) bool {
+	if len(planning.HolidayRules) == 0 || lastDay.Year() <= planning.holidayRulesYear {
+		return false
+	}
+
+	firstYear := planning.holidayRulesYear + 1
+	if planning.holidayRulesYear == 0 {
+		firstYear = planning.StartDay.Year()
+	}
+	// the rules were checked by NewPlanning
+	holidays, err := PublicHolidays(planning.HolidayRules, firstYear, lastDay.Year())
+	if err != nil {
+		return false
+	}
+
+	planning.holidayRulesYear = lastDay.Year()
+	if planning.HolidayNames == nil {
+		planning.HolidayNames = make(map[string]map[Day]string)
+	}
+	if planning.HolidayNames[""] == nil {
+		planning.HolidayNames[""] = make(map[Day]string)
+	}
+	for _, holiday := range holidays {
+		planning.Holidays = append(planning.Holidays, holiday.Day)
+		planning.HolidayNames[""][holiday.Day] = holiday.Name
+	}
+	return len(holidays) > 0
+}
+
+// withPublicHolidays returns a copy of the planning whose holidays go up to the year of the given day, for the analyses
+// that reach beyond the forecast, such as CountOffDays and ScheduleBackward, to leave the planning as it is
+func (planning *Planning) withPublicHolidays(lastDay Day) *Planning {
+	if len(planning.HolidayRules) == 0 || lastDay.Year() <= planning.holidayRulesYear {
+		return planning
+	}
+
+	extended := *planning
+	extended.Holidays = append(Days{}, planning.Holidays...)
+	extended.HolidayNames = make(map[string]map[Day]string, len(planning.HolidayNames))
+	for calendar, names := range planning.HolidayNames {
+		extended.HolidayNames[calendar] = names
+	}
+	extended.HolidayNames[""] = make(map[Day]string, len(planning.HolidayNames[""]))
+	for day, name := range planning.HolidayNames[""] {
+		extended.HolidayNames[""][day] = name
+	}
+	extended.extendPublicHolidays(lastDay)
+	return &extended
+}
+
+func knownHolidayRules() []string {
+	regions := make([]string, 0, len(holidayRules))
+	for region := range holidayRules {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// easterSunday computes the day of Easter Sunday in the Gregorian calendar, with the anonymous Gregorian algorithm
+func easterSunday(year int) Day {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return NewDay(year, time.Month(month), day)
+}
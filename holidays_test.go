@@ -0,0 +1,129 @@
+package planner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEasterSunday(t *testing.T) {
+	examples := []struct {
+		year int
+		exp  string
+	}{
+		{year: 2019, exp: "21/04/2019"},
+		{year: 2021, exp: "04/04/2021"},
+		{year: 2024, exp: "31/03/2024"},
+		{year: 2038, exp: "25/04/2038"},
+	}
+
+	for _, example := range examples {
+		if act := DayToDate(easterSunday(example.year)); act != example.exp {
+			t.Errorf("exp %s, got %s", example.exp, act)
+		}
+	}
+}
+
+func TestPublicHolidays(t *testing.T) {
+	holidays, err := PublicHolidays([]string{"US", "GB-ENG"}, 2021, 2022)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]string)
+	for _, holiday := range holidays {
+		names[DayToDate(holiday.Day)] = holiday.Name
+	}
+
+	examples := map[string]string{
+		"18/01/2021": "Martin Luther King Jr. Day",
+		"31/05/2021": "Memorial Day",
+		"25/11/2021": "Thanksgiving Day",
+		"03/05/2021": "Early May Bank Holiday",
+		"30/08/2021": "Summer Bank Holiday",
+		"02/04/2021": "Good Friday",
+		"24/11/2022": "Thanksgiving Day",
+	}
+	for date, exp := range examples {
+		if names[date] != exp {
+			t.Errorf("exp %s on %s, got %s", exp, date, names[date])
+		}
+	}
+
+	// shared holidays are only generated once
+	christmas := 0
+	for _, holiday := range holidays {
+		if DayToDate(holiday.Day) == "25/12/2021" {
+			christmas++
+		}
+	}
+	if christmas != 1 {
+		t.Errorf("exp christmas once, got %d", christmas)
+	}
+
+	if _, err := PublicHolidays([]string{"XX"}, 2021, 2021); err == nil {
+		t.Errorf("exp an error for unknown rules")
+	}
+}
+
+func TestForecastPublicHolidays(t *testing.T) {
+	utilization := 1.0
+	input := PlanningInput{
+		StartDay:     "01/03/2021",
+		HolidayRules: []string{"FR"},
+		Developers:   []*DeveloperInput{{Id: "dev1", Utilization: &utilization}},
+		Tasks: []*TaskInput{{
+			Name:         "task",
+			Attributions: AttributionInputs{{DevId: "dev1", Attribution: &AttributionInput{Effort: 900}}},
+		}},
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ForecastCompletion(planning)
+
+	// the forecast goes beyond the year of the start day, and so do the public holidays
+	lastDay := planning.Tasks[0].LastDay
+	if lastDay == nil || lastDay.Year() != 2024 {
+		t.Fatalf("exp the task completed in 2024, got %v", lastDay)
+	}
+	labourDay := NewDay(2024, time.May, 1)
+	if NewCalendar(planning).IsWorkingDay("dev1", labourDay) || planning.HolidayNames[""][labourDay] != "Labour Day" {
+		t.Errorf("exp labour day 2024 to be a holiday")
+	}
+
+	// they are still written back as rules
+	if output := NewPlanningInput(planning); len(output.Holidays) != 0 {
+		t.Errorf("exp no holidays written back, got %d", len(output.Holidays))
+	}
+}
+
+func TestAnalysesPublicHolidays(t *testing.T) {
+	input := PlanningInput{
+		StartDay:     "01/03/2021",
+		HolidayRules: []string{"FR"},
+		Developers:   []*DeveloperInput{{Id: "dev1", OffDays: []*DaysInput{{From: "30/04/2024", To: "02/05/2024"}}}},
+		Tasks: []*TaskInput{{
+			Name:         "task",
+			Attributions: AttributionInputs{{DevId: "dev1", Attribution: &AttributionInput{Effort: 1}}},
+		}},
+	}
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ForecastCompletion(planning)
+	holidays := len(planning.Holidays)
+
+	// labour day 2024 is not counted, but the planning is left as it is
+	counts := CountOffDays(planning)
+	if len(counts) != 1 || counts[0].Days != 2 {
+		t.Errorf("exp 2 off days, got %+v", counts)
+	}
+	if _, err := ScheduleBackward(planning, "task", NewDay(2024, time.June, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if len(planning.Holidays) != holidays || planning.HolidayNames[""][NewDay(2024, time.May, 1)] != "" {
+		t.Errorf("exp the holidays of the planning to be unchanged, got %d instead of %d", len(planning.Holidays), holidays)
+	}
+}
@@ -0,0 +1,217 @@
+package planner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ICalEvent is an event of an iCalendar file, reduced to what planner needs
+type ICalEvent struct {
+	Summary   string
+	Attendees []string
+	FirstDay  Day
+	LastDay   Day
+}
+
+// EventMatcher maps iCalendar events to a developer. An event matches if one of its attendees has the given email
+// address, or if its summary matches the given regular expression.
+type EventMatcher struct {
+	Attendee string `yaml:"attendee,omitempty" json:"attendee,omitempty" toml:"attendee,omitempty" description:"Email address of an attendee of the events."`
+	Summary  string `yaml:"summary,omitempty" json:"summary,omitempty" toml:"summary,omitempty" description:"Regular expression the summary of the events matches."`
+}
+
+// ICalendar is an iCalendar file the holidays or the off days of the planning are imported from
+type ICalendar struct {
+	Path string
+	// whether the events are holidays. They apply to everyone, or only to the developers of Calendar if it is set.
+	Holidays bool
+	Calendar string
+	// the events matching a developer are off days of this developer
+	Developers map[DeveloperId]*EventMatcher
+	// the days imported in the planning, which are not written back by NewPlanningInput
+	ImportedHolidays Days
+	ImportedOffDays  map[DeveloperId]Days
+}
+
+// ParseICalendar reads the events of an iCalendar (RFC 5545) file. Recurring events are not supported: only their
+// first occurrence is read. All-day events end the day before their DTEND, as the end of an event is exclusive.
+func ParseICalendar(r io.Reader) ([]*ICalEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*ICalEvent, 0)
+	var event *ICalEvent
+	var start, end *icalTime
+	for i, line := range lines {
+		name, params, value := splitICalLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &ICalEvent{}
+			start, end = nil, nil
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if start == nil {
+				return nil, fmt.Errorf("event %s ending on line %d has no DTSTART", event.Summary, i+1)
+			}
+			event.FirstDay, event.LastDay = start.day, start.day
+			if end != nil {
+				event.LastDay = end.day
+				// the end is exclusive: an event ending at midnight does not cover its last day
+				if end.dateOnly || end.midnight {
+					event.LastDay--
+				}
+			}
+			if event.LastDay < event.FirstDay {
+				event.LastDay = event.FirstDay
+			}
+			events = append(events, event)
+			event = nil
+		case name == "SUMMARY":
+			event.Summary = unescapeICalText(value)
+		case name == "ATTENDEE":
+			attendee := value
+			if strings.HasPrefix(strings.ToLower(attendee), "mailto:") {
+				attendee = attendee[len("mailto:"):]
+			}
+			event.Attendees = append(event.Attendees, attendee)
+		case name == "DTSTART" || name == "DTEND":
+			t, err := parseICalTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("error on line %d: %s", i+1, err)
+			}
+			if name == "DTSTART" {
+				start = t
+			} else {
+				end = t
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// unfoldICalLines joins the lines that are split over several lines, which start with a space or a tab
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitICalLine splits a content line such as DTSTART;VALUE=DATE:20210105 into its name, parameters and value
+func splitICalLine(line string) (string, map[string]string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if eq := strings.Index(param, "="); eq >= 0 {
+			params[strings.ToUpper(param[:eq])] = param[eq+1:]
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+type icalTime struct {
+	day      Day
+	dateOnly bool
+	midnight bool
+}
+
+func parseICalTime(params map[string]string, value string) (*icalTime, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %s", value)
+		}
+		return &icalTime{day: DayOf(t), dateOnly: true}, nil
+	}
+
+	// the days of an event are the ones of the local time: UTC times are converted to it, while the other times are
+	// taken as local ones, whatever their time zone
+	zone := time.Local
+	if strings.HasSuffix(value, "Z") {
+		zone = time.UTC
+	}
+	t, err := time.ParseInLocation("20060102T150405", strings.TrimSuffix(value, "Z"), zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date-time %s", value)
+	}
+	t = t.In(time.Local)
+	midnight := t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+	return &icalTime{day: DayOf(t), midnight: midnight}, nil
+}
+
+func unescapeICalText(text string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(text)
+}
+
+// newICalendar maps the events of the iCalendar input to holidays or developers' off days
+func newICalendar(input *ICalendarInput) (*ICalendar, error) {
+	type compiledMatcher struct {
+		devId    DeveloperId
+		attendee string
+		summary  *regexp.Regexp
+	}
+	matchers := make([]compiledMatcher, 0, len(input.Developers))
+	for devId, matcher := range input.Developers {
+		compiled := compiledMatcher{devId: devId, attendee: strings.ToLower(matcher.Attendee)}
+		if matcher.Summary != "" {
+			summary, err := regexp.Compile(matcher.Summary)
+			if err != nil {
+				return nil, fmt.Errorf("invalid summary pattern of developer %s: %s", devId, err)
+			}
+			compiled.summary = summary
+		}
+		matchers = append(matchers, compiled)
+	}
+
+	calendar := &ICalendar{
+		Path:             input.Path,
+		Holidays:         input.Holidays,
+		Calendar:         input.Calendar,
+		Developers:       input.Developers,
+		ImportedHolidays: make(Days, 0),
+		ImportedOffDays:  make(map[DeveloperId]Days),
+	}
+
+	for _, event := range input.Events {
+		for day := event.FirstDay; day <= event.LastDay; day++ {
+			if input.Holidays {
+				calendar.ImportedHolidays = append(calendar.ImportedHolidays, day)
+			}
+		}
+
+		for _, matcher := range matchers {
+			matches := matcher.summary != nil && matcher.summary.MatchString(event.Summary)
+			for _, attendee := range event.Attendees {
+				matches = matches || (matcher.attendee != "" && strings.ToLower(attendee) == matcher.attendee)
+			}
+			if !matches {
+				continue
+			}
+			for day := event.FirstDay; day <= event.LastDay; day++ {
+				calendar.ImportedOffDays[matcher.devId] = append(calendar.ImportedOffDays[matcher.devId], day)
+			}
+		}
+	}
+
+	return calendar, nil
+}
@@ -0,0 +1,109 @@
+package planner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICalendar(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:19700105\r\n" +
+		"DTEND;VALUE=DATE:19700108\r\n" +
+		"SUMMARY:Vacation\\, Alice\r\n" +
+		"ATTENDEE;CN=Alice:mailto:\r\n" +
+		" alice@example.com\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:19700109T090000Z\r\n" +
+		"DTEND:19700109T120000Z\r\n" +
+		"SUMMARY:Training\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := ParseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("exp 2 events, got %d", len(events))
+	}
+
+	// all-day events end the day before their DTEND
+	vacation := events[0]
+	if vacation.FirstDay != 4 || vacation.LastDay != 6 {
+		t.Errorf("exp vacation from day 4 to 6, got %d to %d", vacation.FirstDay, vacation.LastDay)
+	}
+	if vacation.Summary != "Vacation, Alice" {
+		t.Errorf("exp unescaped summary, got %s", vacation.Summary)
+	}
+	if len(vacation.Attendees) != 1 || vacation.Attendees[0] != "alice@example.com" {
+		t.Errorf("exp unfolded attendee, got %v", vacation.Attendees)
+	}
+
+	training := events[1]
+	if training.FirstDay != 8 || training.LastDay != 8 {
+		t.Errorf("exp training on day 8, got %d to %d", training.FirstDay, training.LastDay)
+	}
+}
+
+func TestParseICalendarUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("HST", -10*60*60)
+	defer func() { time.Local = local }()
+
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:19700109T050000Z\r\n" +
+		"DTEND:19700109T070000Z\r\n" +
+		"SUMMARY:Training\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:19700109T050000\r\n" +
+		"DTEND:19700109T070000\r\n" +
+		"SUMMARY:Review\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := ParseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the UTC morning is the evening before in Hawaii, while floating times are local already
+	if events[0].FirstDay != 7 || events[0].LastDay != 7 {
+		t.Errorf("exp the training on day 7, got %d to %d", events[0].FirstDay, events[0].LastDay)
+	}
+	if events[1].FirstDay != 8 || events[1].LastDay != 8 {
+		t.Errorf("exp the review on day 8, got %d to %d", events[1].FirstDay, events[1].LastDay)
+	}
+}
+
+func TestNewICalendar(t *testing.T) {
+	input := &ICalendarInput{
+		Path: "hr.ics",
+		Developers: map[DeveloperId]*EventMatcher{
+			"alice": {Attendee: "Alice@Example.com"},
+			"bob":   {Summary: "^Bob"},
+		},
+		Events: []*ICalEvent{
+			{Summary: "Vacation", Attendees: []string{"alice@example.com"}, FirstDay: 4, LastDay: 5},
+			{Summary: "Bob - Conference", FirstDay: 6, LastDay: 6},
+			{Summary: "Team offsite", FirstDay: 7, LastDay: 7},
+		},
+	}
+
+	ical, err := newICalendar(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ical.ImportedOffDays["alice"]) != 2 || len(ical.ImportedOffDays["bob"]) != 1 {
+		t.Errorf("exp 2 off days for alice and 1 for bob, got %v", ical.ImportedOffDays)
+	}
+	if len(ical.ImportedHolidays) != 0 {
+		t.Errorf("exp no holidays, got %v", ical.ImportedHolidays)
+	}
+}
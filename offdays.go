@@ -0,0 +1,57 @@
+package planner
+
+// OffDayCount is the number of working days a developer is off for a given type of reason
+type OffDayCount struct {
+	DevId DeveloperId `json:"developer"`
+	// empty for the off days that were given no type
+	Type OffDayType `json:"type"`
+	Days int        `json:"days"`
+}
+
+// CountOffDays counts the off days of every developer by type. Off days falling on a weekend or on one of the
+// developer's holidays are not counted, as they would not have been worked anyway.
+// Counts are sorted by developer, in the planning order, then by type, the untyped off days last.
+func CountOffDays(planning *Planning) []*OffDayCount {
+	types := []OffDayType{Vacation, Sick, Training, Conference, Parental, ""}
+
+	// the public holidays are generated up to the last off day
+	var lastDay Day
+	for _, developer := range planning.Developers {
+		for _, day := range developer.OffDays {
+			if day > lastDay {
+				lastDay = day
+			}
+		}
+	}
+	planning = planning.withPublicHolidays(lastDay)
+
+	counts := make([]*OffDayCount, 0)
+	for _, developer := range planning.Developers {
+		holidays := make(map[Day]bool)
+		for _, day := range planning.Holidays {
+			holidays[day] = true
+		}
+		for _, calendar := range developer.Calendars {
+			for _, day := range planning.Calendars[calendar] {
+				holidays[day] = true
+			}
+		}
+
+		typeToDays := make(map[OffDayType]int)
+		counted := make(map[Day]bool)
+		for _, day := range developer.OffDays {
+			if counted[day] || holidays[day] || isWeekEnd(day) {
+				continue
+			}
+			counted[day] = true
+			typeToDays[developer.OffDayReasons[day].Type]++
+		}
+
+		for _, offDayType := range types {
+			if days := typeToDays[offDayType]; days > 0 {
+				counts = append(counts, &OffDayCount{DevId: developer.Id, Type: offDayType, Days: days})
+			}
+		}
+	}
+	return counts
+}
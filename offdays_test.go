@@ -0,0 +1,73 @@
+package planner
+
+import (
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"testing"
+)
+
+func TestCountOffDays(t *testing.T) {
+	doc := `
+startDay: 01/01/1970
+holidays:
+  - date: 06/01/1970
+    name: Epiphany
+calendars:
+  FR:
+    - date: 07/01/1970
+      name: Galette
+  DE:
+    - date: 07/01/1970
+      name: Knut
+developers:
+  - id: dev1
+    offDays:
+      - 02/01/1970
+      - from: 05/01/1970
+        to: 11/01/1970
+        name: Ski trip
+        type: vacation
+      - date: 12/01/1970
+        type: sick
+`
+	var input PlanningInput
+	if err := yaml.Unmarshal([]byte(doc), &input); err != nil {
+		t.Fatal(err)
+	}
+
+	planning, err := NewPlanning(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, _ := planning.HolidayName("", 5); name != "Epiphany" {
+		t.Errorf("exp the holiday to be named Epiphany, got %s", name)
+	}
+	// calendars name the same day independently
+	frName, _ := planning.HolidayName("FR", 6)
+	deName, _ := planning.HolidayName("DE", 6)
+	if _, prs := planning.HolidayName("", 6); prs || frName != "Galette" || deName != "Knut" {
+		t.Errorf("exp the day to be named by each calendar, got %s and %s", frName, deName)
+	}
+
+	exp := OffDayReason{Name: "Ski trip", Type: Vacation}
+	if reason := planning.Developers[0].OffDayReasons[4]; reason != exp {
+		t.Errorf("exp %+v, got %+v", exp, reason)
+	}
+
+	// the ski trip spans a holiday and a weekend, which are not counted
+	counts := CountOffDays(planning)
+	expCounts := []*OffDayCount{
+		{DevId: "dev1", Type: Vacation, Days: 4},
+		{DevId: "dev1", Type: Sick, Days: 1},
+		{DevId: "dev1", Type: "", Days: 1},
+	}
+	if !reflect.DeepEqual(counts, expCounts) {
+		t.Errorf("exp %v, got %v", expCounts, counts)
+	}
+
+	invalid := []*DaysInput{{Date: "02/01/1970", Type: "holiday"}}
+	if _, err := newDays(invalid, newDateParser(dateFormat)); err == nil {
+		t.Errorf("exp an error for an unknown off day type")
+	}
+}
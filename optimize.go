@@ -0,0 +1,142 @@
+package planner
+
+import "sort"
+
+// WeightedLateness returns the sum, over all the tasks and subtasks with a deadline, of the number of days
+// they are completed after their deadline, multiplied by their weight. Tasks that are not completed are not late, and
+// are counted by UnplannedWeight instead. The planning needs to be forecasted.
+func WeightedLateness(planning *Planning) float64 {
+	lateness := 0.0
+	walkTaskPaths(planning, func(project string, path string, task *Task) {
+		if task.Deadline == nil || task.LastDay == nil || *task.LastDay <= *task.Deadline {
+			return
+		}
+		lateness += task.Weight * float64(*task.LastDay-*task.Deadline)
+	})
+	return lateness
+}
+
+// UnplannedWeight returns the sum of the weights of the tasks and subtasks that the forecast does not complete, as one
+// of their developers leaves or does not work before their attribution is done. The planning needs to be forecasted.
+func UnplannedWeight(planning *Planning) float64 {
+	unplanned := 0.0
+	walkTaskPaths(planning, func(project string, path string, task *Task) {
+		for _, attribution := range task.Attributions {
+			if attribution.LastDay == nil {
+				unplanned += task.Weight
+				return
+			}
+		}
+	})
+	return unplanned
+}
+
+// orderScore is what the optimizer minimizes: the weight of the unplanned tasks first, so that an order is never
+// better for leaving tasks out of the forecast, then the weighted lateness
+type orderScore struct {
+	unplanned float64
+	lateness  float64
+}
+
+func (score orderScore) less(other orderScore) bool {
+	if score.unplanned != other.unplanned {
+		return score.unplanned < other.unplanned
+	}
+	return score.lateness < other.lateness
+}
+
+// OptimizeOrder searches for a priority order of the tasks that minimizes their weighted lateness, and returns it.
+// An order that leaves more tasks unplanned, by weight, is never considered better, whatever its lateness. Only the order of the top-level tasks of the planning and of each project changes: pinned tasks stay first, in their
+// original order, and subtasks keep the order of their parent. The search is a local one, so the order found is not
+// guaranteed to be the best possible, but it is never worse than the original one.
+// The planning is left forecasted with the order found.
+func OptimizeOrder(planning *Planning) float64 {
+	lists := []*[]*Task{&planning.Tasks}
+	for _, project := range planning.Projects {
+		lists = append(lists, &project.Tasks)
+	}
+
+	evaluate := func() orderScore {
+		ForecastCompletion(planning)
+		return orderScore{unplanned: UnplannedWeight(planning), lateness: WeightedLateness(planning)}
+	}
+
+	for _, tasks := range lists {
+		pinnedFirst(*tasks)
+	}
+
+	best := evaluate()
+
+	for _, tasks := range lists {
+		best = optimizeList(tasks, best, evaluate)
+	}
+
+	ForecastCompletion(planning)
+	return best.lateness
+}
+
+// pinnedFirst moves the pinned tasks to the front of the list, keeping the relative order of all tasks
+func pinnedFirst(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Pinned && !tasks[j].Pinned
+	})
+}
+
+// optimizeList reorders the unpinned tasks of the list, first by trying the earliest deadline first order, then by
+// moving tasks one at a time to another position, as long as it decreases the lateness
+func optimizeList(tasks *[]*Task, best orderScore, evaluate func() orderScore) orderScore {
+	list := *tasks
+	nbPinned := 0
+	for nbPinned < len(list) && list[nbPinned].Pinned {
+		nbPinned++
+	}
+	free := list[nbPinned:]
+	if len(free) < 2 {
+		return best
+	}
+
+	original := append([]*Task{}, free...)
+	sort.SliceStable(free, func(i, j int) bool {
+		if free[i].Deadline == nil || free[j].Deadline == nil {
+			return free[i].Deadline != nil
+		}
+		return *free[i].Deadline < *free[j].Deadline
+	})
+	if score := evaluate(); score.less(best) {
+		best = score
+	} else {
+		copy(free, original)
+	}
+
+	improved := true
+	for improved && (best.lateness > 0 || best.unplanned > 0) {
+		improved = false
+		for from := range free {
+			for to := range free {
+				if from == to {
+					continue
+				}
+				moveTask(free, from, to)
+				if score := evaluate(); score.less(best) {
+					best = score
+					improved = true
+					break
+				}
+				moveTask(free, to, from)
+			}
+		}
+	}
+
+	return best
+}
+
+// moveTask moves the task at index from to index to, shifting the tasks in between
+func moveTask(tasks []*Task, from int, to int) {
+	task := tasks[from]
+	if from < to {
+		copy(tasks[from:to], tasks[from+1:to+1])
+	} else {
+		copy(tasks[to+1:from+1], tasks[to:from])
+	}
+	tasks[to] = task
+}
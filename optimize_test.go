@@ -0,0 +1,98 @@
+package planner
+
+import "testing"
+
+func TestOptimizeOrder(t *testing.T) {
+	var day5 Day = 5
+	var day8 Day = 8
+	newTask := func(name string, effort EffortDays, deadline *Day, pinned bool) *Task {
+		return &Task{
+			Name:         name,
+			Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: effort}},
+			Deadline:     deadline,
+			Weight:       1,
+			Pinned:       pinned,
+		}
+	}
+
+	pinned := newTask("pinned", 1, nil, true)
+	long := newTask("long", 3, nil, false)
+	urgent := newTask("urgent", 1, &day5, false)
+	soon := newTask("soon", 2, &day8, false)
+
+	planning := &Planning{
+		StartDay:   4,
+		Developers: []*Developer{{Id: "dev1", Utilization: 1}},
+		Tasks:      []*Task{long, urgent, soon, pinned},
+	}
+
+	lateness := OptimizeOrder(planning)
+
+	// saturdays + sundays: 9, 10
+	// pinned (1d): 4
+	// urgent (1d): 5
+	// soon (2d): 6, 7
+	// long (3d): 8, 11, 12
+	if lateness != 0 {
+		t.Errorf("exp no lateness, got %v", lateness)
+	}
+
+	exp := []*Task{pinned, urgent, soon, long}
+	for i, task := range planning.Tasks {
+		if task != exp[i] {
+			t.Errorf("exp %s at position %d, got %s", exp[i].Name, i, task.Name)
+		}
+	}
+
+	if *soon.LastDay != 7 {
+		t.Errorf("exp planning to be forecasted with the order found, got last day %d", *soon.LastDay)
+	}
+}
+
+func TestWeightedLateness(t *testing.T) {
+	var day5 Day = 5
+	var day8 Day = 8
+	planning := &Planning{
+		Tasks: []*Task{
+			{Name: "late", Deadline: &day5, LastDay: &day8, Weight: 2},
+			{Name: "on time", Deadline: &day8, LastDay: &day5, Weight: 1},
+			{Name: "no deadline", LastDay: &day8, Weight: 1},
+		},
+	}
+
+	if lateness := WeightedLateness(planning); lateness != 6 {
+		t.Errorf("exp 6, got %v", lateness)
+	}
+}
+
+func TestOptimizeOrderUnplanned(t *testing.T) {
+	var day4 Day = 4
+	var day11 Day = 11
+	var leaves Day = 14
+	important := &Task{
+		Name:         "important",
+		Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 9}},
+		Deadline:     &day11,
+		Weight:       10,
+	}
+	urgent := &Task{
+		Name:         "urgent",
+		Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 2}},
+		Deadline:     &day4,
+		Weight:       1,
+	}
+	planning := &Planning{
+		StartDay:   4,
+		Developers: []*Developer{{Id: "dev1", Utilization: 1, Leaves: &leaves}},
+		Tasks:      []*Task{important, urgent},
+	}
+
+	// dev1 works 9 days before leaving: putting urgent first would leave important unplanned, and not late
+	lateness := OptimizeOrder(planning)
+	if planning.Tasks[0] != important || important.LastDay == nil {
+		t.Fatalf("exp important to stay first and planned, got %s first", planning.Tasks[0].Name)
+	}
+	if lateness != 30 || UnplannedWeight(planning) != 1 {
+		t.Errorf("exp a lateness of 30 and urgent unplanned, got %v and %v", lateness, UnplannedWeight(planning))
+	}
+}
@@ -0,0 +1,226 @@
+package planner
+
+import "fmt"
+
+// ApplyOverlay layers an overlay planning input on top of a base planning input, so that scenarios can be described
+// by their differences with the base planning:
+// - the start day is replaced, if set
+// - holidays, holiday rules, calendar holidays, iCalendar files and support weeks are added
+// - developers are matched by id. New developers are added, while the off days of existing ones are added, and their
+// start and leave dates, utilization, allocations and calendars are replaced, if set
+// - tasks are matched by name, and projects by name. New tasks are added after the existing ones, while the attributions
+// of existing tasks are added, or have their effort replaced, and their subtasks are merged the same way
+func ApplyOverlay(base *PlanningInput, overlay *PlanningInput) {
+	if overlay.StartDay != "" {
+		base.StartDay = overlay.StartDay
+	}
+
+	base.Holidays = append(base.Holidays, overlay.Holidays...)
+	base.HolidayRules = append(base.HolidayRules, overlay.HolidayRules...)
+	for name, dates := range overlay.Calendars {
+		if base.Calendars == nil {
+			base.Calendars = make(map[string][]*DaysInput, len(overlay.Calendars))
+		}
+		base.Calendars[name] = append(base.Calendars[name], dates...)
+	}
+	base.ICalendars = append(base.ICalendars, overlay.ICalendars...)
+	base.SupportWeeks = append(base.SupportWeeks, overlay.SupportWeeks...)
+
+	// the items added by the overlay keep their position in it
+	for item, position := range overlay.positions {
+		if base.positions == nil {
+			base.positions = make(map[interface{}]*sourcePosition, len(overlay.positions))
+		}
+		base.positions[item] = position
+	}
+
+	for _, developer := range overlay.Developers {
+		base.Developers = overlayDeveloper(base.Developers, developer)
+	}
+
+	base.Tasks = overlayTasks(base.Tasks, overlay.Tasks)
+
+	for _, project := range overlay.Projects {
+		found := false
+		for _, baseProject := range base.Projects {
+			if baseProject.Name == project.Name {
+				baseProject.Tasks = overlayTasks(baseProject.Tasks, project.Tasks)
+				found = true
+				break
+			}
+		}
+		if !found {
+			base.Projects = append(base.Projects, project)
+		}
+	}
+}
+
+func overlayDeveloper(developers []*DeveloperInput, overlay *DeveloperInput) []*DeveloperInput {
+	for _, developer := range developers {
+		if developer.Id != overlay.Id {
+			continue
+		}
+
+		developer.OffDays = append(developer.OffDays, overlay.OffDays...)
+		if overlay.Starts != nil {
+			developer.Starts = overlay.Starts
+		}
+		if overlay.Leaves != nil {
+			developer.Leaves = overlay.Leaves
+		}
+		if overlay.Utilization != nil {
+			developer.Utilization = overlay.Utilization
+		}
+		if overlay.Allocations != nil {
+			developer.Allocations = overlay.Allocations
+		}
+		if overlay.RampUp != nil {
+			developer.RampUp = overlay.RampUp
+		}
+		if overlay.Calendars != nil {
+			developer.Calendars = overlay.Calendars
+		}
+		return developers
+	}
+	return append(developers, overlay)
+}
+
+func overlayTasks(tasks []*TaskInput, overlays []*TaskInput) []*TaskInput {
+	for _, overlay := range overlays {
+		found := false
+		for _, task := range tasks {
+			if taskKey(task.Id, task.Name) != taskKey(overlay.Id, overlay.Name) {
+				continue
+			}
+
+			// added attributions come after the ones of the base task
+			for _, attribution := range overlay.Attributions {
+				if baseAttribution := task.Attributions.Get(attribution.DevId); baseAttribution != nil {
+					baseAttribution.Effort = attribution.Attribution.Effort
+				} else {
+					task.Attributions = append(task.Attributions, attribution)
+				}
+			}
+			task.Subtasks = overlayTasks(task.Subtasks, overlay.Subtasks)
+			found = true
+			break
+		}
+		if !found {
+			tasks = append(tasks, overlay)
+		}
+	}
+	return tasks
+}
+
+// TaskDiff is the difference between the forecasts of a task in two plannings
+type TaskDiff struct {
+	// the project of the task, followed by the names of its parents and its own name, separated by " / "
+	Path            string
+	BaseLastDay     *Day
+	ScenarioLastDay *Day
+}
+
+func (diff *TaskDiff) String() string {
+	return diff.Format(DayToDate)
+}
+
+// Format describes the difference, with the days written by formatDate, such as the FormatDate of the planning
+func (diff *TaskDiff) Format(formatDate func(Day) string) string {
+	switch {
+	case diff.BaseLastDay == nil && diff.ScenarioLastDay == nil:
+		return fmt.Sprintf("%s: not planned", diff.Path)
+	case diff.BaseLastDay == nil:
+		return fmt.Sprintf("%s: added, completed on %s", diff.Path, formatDate(*diff.ScenarioLastDay))
+	case diff.ScenarioLastDay == nil:
+		return fmt.Sprintf("%s: removed, was completed on %s", diff.Path, formatDate(*diff.BaseLastDay))
+	default:
+		return fmt.Sprintf("%s: completed on %s instead of %s (%+d days)", diff.Path,
+			formatDate(*diff.ScenarioLastDay), formatDate(*diff.BaseLastDay), *diff.ScenarioLastDay-*diff.BaseLastDay)
+	}
+}
+
+// DiffForecasts compares the last days of the tasks of two forecasted plannings, and returns the tasks whose last day
+// changed, in the scenario priority order, followed by the tasks that only exist in the base planning.
+// Tasks are matched by id, so that a renamed or moved task is still compared, or by path when they have none. Tasks
+// sharing a path are matched in order.
+func DiffForecasts(base *Planning, scenario *Planning) []*TaskDiff {
+	baseLastDays := make(map[string]*Day)
+	basePaths := make(map[string]string)
+	baseKeys := make([]string, 0)
+	baseOccurrences := make(map[string]int)
+	walkTaskPaths(base, func(project string, path string, task *Task) {
+		key := occurrenceKey(baseOccurrences, taskKey(task.Id, path))
+		baseLastDays[key] = task.LastDay
+		basePaths[key] = path
+		baseKeys = append(baseKeys, key)
+	})
+
+	diffs := make([]*TaskDiff, 0)
+	scenarioKeys := make(map[string]bool)
+	scenarioOccurrences := make(map[string]int)
+	walkTaskPaths(scenario, func(project string, path string, task *Task) {
+		key := occurrenceKey(scenarioOccurrences, taskKey(task.Id, path))
+		scenarioKeys[key] = true
+		baseLastDay, prs := baseLastDays[key]
+		if prs && equalDays(baseLastDay, task.LastDay) {
+			return
+		}
+		diffs = append(diffs, &TaskDiff{
+			Path:            path,
+			BaseLastDay:     baseLastDay,
+			ScenarioLastDay: task.LastDay,
+		})
+	})
+
+	for _, key := range baseKeys {
+		if !scenarioKeys[key] {
+			diffs = append(diffs, &TaskDiff{
+				Path:        basePaths[key],
+				BaseLastDay: baseLastDays[key],
+			})
+		}
+	}
+
+	return diffs
+}
+
+// occurrenceKey tells apart the tasks that share a key, by the number of times the key was seen before
+func occurrenceKey(occurrences map[string]int, key string) string {
+	occurrences[key]++
+	if occurrences[key] == 1 {
+		return key
+	}
+	return fmt.Sprintf("%s (%d)", key, occurrences[key])
+}
+
+// taskKey identifies a task by its id, or by its name or path when it has none
+func taskKey(id string, name string) string {
+	if id != "" {
+		return "#" + id
+	}
+	return name
+}
+
+func equalDays(a *Day, b *Day) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// walkTaskPaths calls fn on every task of the planning in priority order, along with its project and its path
+func walkTaskPaths(planning *Planning, fn func(project string, path string, task *Task)) {
+	var walk func(project string, prefix string, tasks []*Task)
+	walk = func(project string, prefix string, tasks []*Task) {
+		for _, task := range tasks {
+			path := prefix + task.Name
+			fn(project, path, task)
+			walk(project, path+" / ", task.Subtasks)
+		}
+	}
+
+	walk("", "", planning.Tasks)
+	for _, project := range planning.Projects {
+		walk(project.Name, project.Name+" / ", project.Tasks)
+	}
+}
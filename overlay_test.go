@@ -0,0 +1,158 @@
+package planner
+
+import "testing"
+
+func TestApplyOverlay(t *testing.T) {
+	utilization := 0.5
+	base := &PlanningInput{
+		StartDay: "04/01/2021",
+		Holidays: []*DaysInput{{Date: "05/01/2021"}},
+		Developers: []*DeveloperInput{
+			{Id: "dev1", OffDays: []*DaysInput{{Date: "06/01/2021"}}},
+		},
+		Tasks: []*TaskInput{
+			{
+				Name: "task1",
+				Attributions: AttributionInputs{
+					{DevId: "dev1", Attribution: &AttributionInput{Effort: 5}},
+				},
+			},
+		},
+	}
+	overlay := &PlanningInput{
+		Developers: []*DeveloperInput{
+			{Id: "dev1", OffDays: []*DaysInput{{Date: "07/01/2021"}}, Utilization: &utilization, RampUp: []RampUpStep{{Weeks: 1, Utilization: 0.5}}},
+			{Id: "dev2"},
+		},
+		Tasks: []*TaskInput{
+			{
+				Name: "task1",
+				Attributions: AttributionInputs{
+					{DevId: "dev1", Attribution: &AttributionInput{Effort: 3}},
+					{DevId: "dev2", Attribution: &AttributionInput{Effort: 2}},
+				},
+			},
+			{
+				Name: "task2",
+				Attributions: AttributionInputs{
+					{DevId: "dev2", Attribution: &AttributionInput{Effort: 1}},
+				},
+			},
+		},
+	}
+
+	ApplyOverlay(base, overlay)
+
+	if base.StartDay != "04/01/2021" {
+		t.Errorf("exp start day to be kept, got %s", base.StartDay)
+	}
+	if len(base.Developers) != 2 || base.Developers[1].Id != "dev2" {
+		t.Fatalf("exp dev2 to be added, got %+v", base.Developers)
+	}
+	if len(base.Developers[0].OffDays) != 2 {
+		t.Errorf("exp off days to be added, got %v", base.Developers[0].OffDays)
+	}
+	if *base.Developers[0].Utilization != 0.5 {
+		t.Errorf("exp utilization to be replaced, got %v", *base.Developers[0].Utilization)
+	}
+	if len(base.Developers[0].RampUp) != 1 {
+		t.Errorf("exp ramp-up to be replaced, got %v", base.Developers[0].RampUp)
+	}
+	if len(base.Tasks) != 2 || base.Tasks[1].Name != "task2" {
+		t.Fatalf("exp task2 to be added, got %+v", base.Tasks)
+	}
+	if base.Tasks[0].Attributions.Get("dev1").Effort != 3 {
+		t.Errorf("exp effort to be replaced, got %d", base.Tasks[0].Attributions.Get("dev1").Effort)
+	}
+	if attribution := base.Tasks[0].Attributions.Get("dev2"); attribution == nil || attribution.Effort != 2 {
+		t.Errorf("exp attribution to be added, got %+v", base.Tasks[0].Attributions)
+	}
+}
+
+func TestDiffForecasts(t *testing.T) {
+	var day5 Day = 5
+	var day6 Day = 6
+	var day7 Day = 7
+	base := &Planning{
+		Tasks: []*Task{
+			{Name: "task1", LastDay: &day5},
+			{Name: "task2", LastDay: &day6},
+			{Name: "task3", LastDay: &day6},
+		},
+	}
+	scenario := &Planning{
+		Tasks: []*Task{
+			{Name: "task1", LastDay: &day5},
+			{Name: "task2", LastDay: &day7},
+			{Name: "task4", LastDay: &day7},
+		},
+	}
+
+	diffs := DiffForecasts(base, scenario)
+
+	exp := []string{
+		"task2: completed on 08/01/1970 instead of 07/01/1970 (+1 days)",
+		"task4: added, completed on 08/01/1970",
+		"task3: removed, was completed on 07/01/1970",
+	}
+
+	if len(diffs) != len(exp) {
+		t.Fatalf("exp %d diffs, got %v", len(exp), diffs)
+	}
+	for i, diff := range diffs {
+		if diff.String() != exp[i] {
+			t.Errorf("exp %s, got %s", exp[i], diff)
+		}
+	}
+
+	// the days are written in the format of the planning
+	scenario.dateLayout = isoDateFormat
+	if act := diffs[0].Format(scenario.FormatDate); act != "task2: completed on 1970-01-08 instead of 1970-01-07 (+1 days)" {
+		t.Errorf("exp the days in the ISO format, got %s", act)
+	}
+}
+
+func TestDiffForecastsIds(t *testing.T) {
+	var day5 Day = 5
+	var day6 Day = 6
+	base := &Planning{
+		Tasks: []*Task{
+			{Id: "T-1", Name: "task1", LastDay: &day5},
+		},
+	}
+	scenario := &Planning{
+		Tasks: []*Task{
+			{Id: "T-1", Name: "renamed task1", LastDay: &day6},
+		},
+	}
+
+	diffs := DiffForecasts(base, scenario)
+
+	exp := "renamed task1: completed on 07/01/1970 instead of 06/01/1970 (+1 days)"
+	if len(diffs) != 1 || diffs[0].String() != exp {
+		t.Errorf("exp %s, got %v", exp, diffs)
+	}
+}
+
+func TestDiffForecastsDuplicatePaths(t *testing.T) {
+	var day5 Day = 5
+	var day6 Day = 6
+	var day7 Day = 7
+	base := &Planning{
+		Tasks: []*Task{
+			{Name: "task", Subtasks: []*Task{{Name: "review", LastDay: &day5}, {Name: "review", LastDay: &day6}}},
+		},
+	}
+	scenario := &Planning{
+		Tasks: []*Task{
+			{Name: "task", Subtasks: []*Task{{Name: "review", LastDay: &day5}, {Name: "review", LastDay: &day7}}},
+		},
+	}
+
+	// the subtasks sharing a name are compared in order, rather than the last one overwriting the first
+	diffs := DiffForecasts(base, scenario)
+	exp := "task / review: completed on 08/01/1970 instead of 07/01/1970 (+1 days)"
+	if len(diffs) != 1 || diffs[0].String() != exp {
+		t.Errorf("exp %s, got %v", exp, diffs)
+	}
+}
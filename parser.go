@@ -1,119 +1,411 @@
 package planner
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type PlanningInput struct {
-	StartDay     string `yaml:"startDay"`
-	Holidays     []string
-	Developers   []*DeveloperInput   `yaml:"developers"`
-	SupportWeeks []*SupportWeekInput `yaml:"supportWeeks"`
-	Tasks        []*TaskInput        `yaml:"tasks"`
+	// format of the dates of the planning and of its roster, such as MM/dd/yyyy. Defaults to dd/MM/yyyy.
+	// Dates in the ISO 8601 format, yyyy-MM-dd, are accepted whatever the format.
+	DateFormat string `yaml:"dateFormat,omitempty" json:"dateFormat,omitempty" toml:"dateFormat,omitempty" description:"Format of the dates, made of dd, MM and yyyy, such as MM/dd/yyyy. Defaults to dd/MM/yyyy. ISO 8601 dates are accepted whatever the format."`
+	StartDay   string `yaml:"startDay" json:"startDay" toml:"startDay" description:"The start of the planning: effort is allocated to tasks from this day on." schema:"required"`
+	// path to a roster file, relative to the planning file. When set, developers, holidays and support weeks
+	// are read from the roster, so that they can be shared between several planning files.
+	Roster string `yaml:"roster,omitempty" json:"roster,omitempty" toml:"roster,omitempty" description:"Path to a roster file, relative to the planning file, with the developers, holidays and support weeks."`
+	// paths to files the holidays, developers, support weeks and tasks are also read from, relative to the planning
+	// file, so that different people can own them. Their entries come after the ones of the planning, in file order.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty" toml:"include,omitempty" description:"Paths to files the holidays, developers, support weeks and tasks are also read from, relative to the planning file."`
+	// filled by ReadPlanningInput, in the order of the paths above. Their entries are also in the fields below.
+	Included     []*IncludeInput         `yaml:"-" json:"-" toml:"-"`
+	Holidays     []*DaysInput            `yaml:",omitempty" json:"holidays,omitempty" toml:"holidays,omitempty" description:"Holidays of every developer."`
+	HolidayRules []string                `yaml:"holidayRules,omitempty" json:"holidayRules,omitempty" toml:"holidayRules,omitempty" description:"Countries or regions whose public holidays are added to the holidays, from the year of the start day and for as many years as the forecast runs."`
+	Calendars    map[string][]*DaysInput `yaml:"calendars,omitempty" json:"calendars,omitempty" toml:"calendars,omitempty" description:"Named holiday calendars, that only apply to the developers who use them."`
+	ICalendars   []*ICalendarInput       `yaml:"icalendars,omitempty" json:"icalendars,omitempty" toml:"icalendars,omitempty" description:"iCalendar files to import holidays and off days from."`
+	Developers   []*DeveloperInput       `yaml:"developers,omitempty" json:"developers,omitempty" toml:"developers,omitempty" description:"The developers staffed on the tasks."`
+	SupportWeeks []*SupportWeekInput     `yaml:"supportWeeks,omitempty" json:"supportWeeks,omitempty" toml:"supportWeeks,omitempty" description:"Periods during which a developer is pulled from feature work to work on support."`
+	// default ramp-up of the developers who have a start day
+	RampUp   []RampUpStep    `yaml:"rampUp,omitempty" json:"rampUp,omitempty" toml:"rampUp,omitempty" description:"Ramp-up of the developers who have a start day, as utilization steps on top of their utilization."`
+	Tasks    []*TaskInput    `yaml:"tasks,omitempty" json:"tasks,omitempty" toml:"tasks,omitempty" description:"Tasks, in priority order, highest priority first."`
+	Projects []*ProjectInput `yaml:"projects,omitempty" json:"projects,omitempty" toml:"projects,omitempty" description:"Projects, each with its own task priority list."`
+	// write-only, computed by planner
+	CriticalPath []*CriticalStepInput `yaml:"criticalPath,omitempty" json:"criticalPath,omitempty" toml:"criticalPath,omitempty" description:"The chain of attributions that decides the last day of the planning." schema:"computed"`
+	// filled by ReadPlanningInput with the planning files the roster lists as sharing it, relative to the working
+	// directory
+	RosterPlannings []string `yaml:"-" json:"-" toml:"-"`
+	// positions of the items in the files they were read from, filled by ReadPlanningInput
+	positions map[interface{}]*sourcePosition
+}
+
+// errorAt returns the error at the position of the input item in the file it was read from, when it is known
+func (input *PlanningInput) errorAt(item interface{}, err error) error {
+	if position, prs := input.positions[item]; prs {
+		return position.errorf("%s", err)
+	}
+	return err
+}
+
+type RosterInput struct {
+	Holidays     []*DaysInput            `yaml:"holidays" json:"holidays" toml:"holidays" description:"Holidays of every developer."`
+	HolidayRules []string                `yaml:"holidayRules" json:"holidayRules" toml:"holidayRules" description:"Countries or regions whose public holidays are added to the holidays, from the year of the start day and for as many years as the forecast runs."`
+	Calendars    map[string][]*DaysInput `yaml:"calendars" json:"calendars" toml:"calendars" description:"Named holiday calendars, that only apply to the developers who use them."`
+	ICalendars   []*ICalendarInput       `yaml:"icalendars" json:"icalendars" toml:"icalendars" description:"iCalendar files to import holidays and off days from."`
+	Developers   []*DeveloperInput       `yaml:"developers" json:"developers" toml:"developers" description:"The developers staffed on the tasks."`
+	SupportWeeks []*SupportWeekInput     `yaml:"supportWeeks" json:"supportWeeks" toml:"supportWeeks" description:"Periods during which a developer is pulled from feature work to work on support."`
+	// planning files sharing the roster, relative to the roster file
+	Plannings []string `yaml:"plannings" json:"plannings" toml:"plannings" description:"Paths to the planning files that share the roster, relative to the roster file, checked together so that they do not book a developer beyond their capacity."`
+}
+
+// IncludeInput is a file included by a planning, with part of its holidays, developers, support weeks and tasks
+type IncludeInput struct {
+	Holidays     []*DaysInput        `yaml:"holidays,omitempty" json:"holidays,omitempty" toml:"holidays,omitempty" description:"Holidays of every developer."`
+	Developers   []*DeveloperInput   `yaml:"developers,omitempty" json:"developers,omitempty" toml:"developers,omitempty" description:"The developers staffed on the tasks."`
+	SupportWeeks []*SupportWeekInput `yaml:"supportWeeks,omitempty" json:"supportWeeks,omitempty" toml:"supportWeeks,omitempty" description:"Periods during which a developer is pulled from feature work to work on support."`
+	Tasks        []*TaskInput        `yaml:"tasks,omitempty" json:"tasks,omitempty" toml:"tasks,omitempty" description:"Tasks, in priority order, after the tasks of the planning and of the files included before."`
+}
+
+type ICalendarInput struct {
+	// path to the iCalendar file, relative to the file that refers to it
+	Path string `yaml:"path" json:"path" toml:"path" description:"Path to the iCalendar file, relative to the file that refers to it." schema:"required"`
+	// whether all the events are holidays. When a calendar is set, they are holidays of this calendar only.
+	Holidays bool   `yaml:"holidays,omitempty" json:"holidays,omitempty" toml:"holidays,omitempty" description:"Whether all the events are holidays."`
+	Calendar string `yaml:"calendar,omitempty" json:"calendar,omitempty" toml:"calendar,omitempty" description:"The holiday calendar the events are added to, when they are holidays."`
+	// maps the events to the off days of developers
+	Developers map[DeveloperId]*EventMatcher `yaml:"developers,omitempty" json:"developers,omitempty" toml:"developers,omitempty" description:"Events matched to the off days of developers, by developer id."`
+	// filled by ReadPlanningInput
+	Events []*ICalEvent `yaml:"-" json:"-" toml:"-"`
+}
+
+type CriticalStepInput struct {
+	Task     string      `yaml:"task" json:"task" toml:"task" description:"Path of the task."`
+	DevId    DeveloperId `yaml:"devId" json:"devId" toml:"devId" description:"The developer of the attribution."`
+	FirstDay string      `yaml:"firstDay" json:"firstDay" toml:"firstDay" description:"The first day of the attribution."`
+	LastDay  string      `yaml:"lastDay" json:"lastDay" toml:"lastDay" description:"The last day of the attribution."`
+	OffDays  []string    `yaml:"offDays,omitempty" json:"offDays,omitempty" toml:"offDays,omitempty" description:"The off days, holidays and support days that delayed the attribution."`
+}
+
+type ProjectInput struct {
+	Name  string       `yaml:"name" json:"name" toml:"name" description:"Name of the project." schema:"required"`
+	Tasks []*TaskInput `yaml:"tasks" json:"tasks" toml:"tasks" description:"Tasks of the project, in priority order, highest priority first."`
 }
 
 type TaskInput struct {
-	Name         string
-	Attributions map[DeveloperId]*AttributionInput
+	// optional stable identifier, so that the task can be renamed
+	Id           string            `yaml:"id,omitempty" json:"id,omitempty" toml:"id,omitempty" description:"Optional stable identifier of the task, unique among all the tasks, so that the task can be renamed."`
+	Name         string            `yaml:"name" json:"name" toml:"name" description:"Name of the task." schema:"required"`
+	Attributions AttributionInputs `yaml:",omitempty" json:"attributions,omitempty" toml:"attributions,omitempty" description:"Effort of the developers staffed on the task, by developer id."`
+	Subtasks     []*TaskInput      `yaml:"subtasks,omitempty" json:"subtasks,omitempty" toml:"subtasks,omitempty" description:"Subtasks, scheduled in tree order, after the attributions of the task."`
+	FirstDay     *string           `yaml:"firstDay,omitempty" json:"firstDay,omitempty" toml:"firstDay,omitempty" description:"The first day of work on the task." schema:"computed"`
+	LastDay      *string           `yaml:"lastDay,omitempty" json:"lastDay,omitempty" toml:"lastDay,omitempty" description:"The day the task is completed." schema:"computed"`
+	Deadline     *string           `yaml:"deadline,omitempty" json:"deadline,omitempty" toml:"deadline,omitempty" description:"The day the task should be completed by."`
+	Weight       *float64          `yaml:"weight,omitempty" json:"weight,omitempty" toml:"weight,omitempty" description:"The business value of the task, used by the optimizer. Defaults to 1."`
+	Pinned       bool              `yaml:"pinned,omitempty" json:"pinned,omitempty" toml:"pinned,omitempty" description:"Whether the task stays first when the priority order is optimized."`
+	Slack        *int              `yaml:"slack,omitempty" json:"slack,omitempty" toml:"slack,omitempty" description:"The number of working days the task can slip without delaying the planning." schema:"computed"`
+}
+
+// AttributionInputs are the attributions of a task, in the order of the file. They are written as a table keyed by
+// developer id, in this order.
+type AttributionInputs []*DeveloperAttributionInput
+
+// DeveloperAttributionInput is the attribution of a task to a developer
+type DeveloperAttributionInput struct {
+	DevId       DeveloperId
+	Attribution *AttributionInput
+}
+
+// Get returns the attribution of the developer, or nil if there is none
+func (attributions AttributionInputs) Get(devId DeveloperId) *AttributionInput {
+	for _, attribution := range attributions {
+		if attribution.DevId == devId {
+			return attribution.Attribution
+		}
+	}
+	return nil
+}
+
+// order sorts the attributions in the order of the developers, see orderDeveloperIds
+func (attributions AttributionInputs) order(devIds []DeveloperId) {
+	byDeveloper := make(map[DeveloperId]*DeveloperAttributionInput, len(attributions))
+	for _, attribution := range attributions {
+		byDeveloper[attribution.DevId] = attribution
+	}
+	for i, devId := range orderDeveloperIds(attributions.developerIds(), devIds) {
+		attributions[i] = byDeveloper[devId]
+	}
+}
+
+// developerIds returns the developers of the attributions, in order
+func (attributions AttributionInputs) developerIds() []DeveloperId {
+	devIds := make([]DeveloperId, len(attributions))
+	for i, attribution := range attributions {
+		devIds[i] = attribution.DevId
+	}
+	return devIds
 }
 
 type AttributionInput struct {
-	Effort   EffortDays
-	FirstDay *string `yaml:"firstDay"`
-	LastDay  *string `yaml:"lastDay"`
+	Effort   EffortDays `yaml:"effort" json:"effort" toml:"effort" description:"Effort, in work days." schema:"required"`
+	FirstDay *string    `yaml:"firstDay" json:"firstDay" toml:"firstDay" description:"The first day of work of the developer on the task." schema:"computed"`
+	LastDay  *string    `yaml:"lastDay" json:"lastDay" toml:"lastDay" description:"The last day of work of the developer on the task." schema:"computed"`
+	Slack    *int       `yaml:"slack,omitempty" json:"slack,omitempty" toml:"slack,omitempty" description:"The number of working days the attribution can slip without delaying the planning." schema:"computed"`
+	Critical bool       `yaml:"critical,omitempty" json:"critical,omitempty" toml:"critical,omitempty" description:"Whether the attribution is on the critical path." schema:"computed"`
 }
 
 type SupportWeekInput struct {
-	FirstDay string      `yaml:"firstDay"`
-	LastDay  string      `yaml:"lastDay"`
-	DevId    DeveloperId `yaml:"devId"`
+	FirstDay string      `yaml:"firstDay" json:"firstDay" toml:"firstDay" description:"The first day of support." schema:"required"`
+	LastDay  string      `yaml:"lastDay" json:"lastDay" toml:"lastDay" description:"The last day of support, included." schema:"required"`
+	DevId    DeveloperId `yaml:"devId" json:"devId" toml:"devId" description:"The developer on support." schema:"required"`
 }
 
 type DeveloperInput struct {
-	Id      DeveloperId
-	OffDays []string `yaml:"offDays"`
-	Starts  *string  `yaml:"starts,omitempty"`
-	Leaves    *string  `yaml:"leaves,omitempty"`
-	Utilization *float64 `yaml:"utilization"`
+	Id          DeveloperId        `yaml:"id" json:"id" toml:"id" description:"Unique id of the developer." schema:"required"`
+	OffDays     []*DaysInput       `yaml:"offDays" json:"offDays" toml:"offDays" description:"Days that are not worked by the developer."`
+	Starts      *string            `yaml:"starts,omitempty" json:"starts,omitempty" toml:"starts,omitempty" description:"First work day, when the developer joins the team or the company."`
+	Leaves      *string            `yaml:"leaves,omitempty" json:"leaves,omitempty" toml:"leaves,omitempty" description:"Last work day, when the developer leaves the team or the company."`
+	Utilization *float64           `yaml:"utilization" json:"utilization" toml:"utilization" description:"Part of the time the developer is assigned to feature work. Defaults to 1."`
+	Allocations map[string]float64 `yaml:"allocations,omitempty" json:"allocations,omitempty" toml:"allocations,omitempty" description:"Part of the capacity of the developer allocated to each project, by project name."`
+	RampUp      []RampUpStep       `yaml:"rampUp,omitempty" json:"rampUp,omitempty" toml:"rampUp,omitempty" description:"Ramp-up of the developer after their start day. Overrides the ramp-up of the planning."`
+	Calendars   []string           `yaml:"calendars,omitempty" json:"calendars,omitempty" toml:"calendars,omitempty" description:"The holiday calendars that apply to the developer, on top of the holidays."`
 }
 
 func NewPlanning(input PlanningInput) (*Planning, error) {
-	holidays := make([]Day, len(input.Holidays))
-	for i, s := range input.Holidays {
-		d, err := DateToDay(s)
+	layout := dateFormat
+	if input.DateFormat != "" {
+		var err error
+		layout, err = DateFormatLayout(input.DateFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the start day is parsed first, as other dates can be relative to it
+	dates := newDateParser(layout)
+	startDay, err := dates.parseField("startDay", input.StartDay)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing start day: %s", err)
+	}
+	dates.startDay = &startDay
+
+	// without a format of its own, a planning whose start day is an ISO date is written back with ISO dates
+	outputLayout := layout
+	if _, err := time.Parse(isoDateFormat, input.StartDay); err == nil && input.DateFormat == "" {
+		outputLayout = isoDateFormat
+	}
+
+	holidays, err := newDays(input.Holidays, dates)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cal %s", err)
+	}
+
+	// holidays of the planning and of the calendars keep the names they were given
+	holidayNames := map[string]map[Day]string{"": make(map[Day]string)}
+	if err := newDayNames(input.Holidays, holidayNames[""], dates); err != nil {
+		return nil, fmt.Errorf("error parsing cal %s", err)
+	}
+
+	calendars := make(map[string]Days, len(input.Calendars))
+	for name, entries := range input.Calendars {
+		days, err := newDays(entries, dates)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing cal %s", err)
+			return nil, fmt.Errorf("error parsing calendar %s: %s", name, err)
+		}
+		holidayNames[name] = make(map[Day]string)
+		if err := newDayNames(entries, holidayNames[name], dates); err != nil {
+			return nil, fmt.Errorf("error parsing calendar %s: %s", name, err)
 		}
-		holidays[i] = d
+		calendars[name] = days
 	}
 
 	devs := make([]*Developer, len(input.Developers))
 
-	for i, input := range input.Developers {
-		dev, err := newDeveloper(input)
+	for i, devInput := range input.Developers {
+		dev, err := newDeveloper(devInput, dates)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing developer %s", err)
+			return nil, input.errorAt(devInput, fmt.Errorf("error parsing developer %s", err))
 		}
 		devs[i] = dev
 	}
 
+	// imported days are added to the holidays, calendars and off days, as if they were part of the planning
+	icals := make([]*ICalendar, len(input.ICalendars))
+	for i, input := range input.ICalendars {
+		ical, err := newICalendar(input)
+		if err != nil {
+			return nil, fmt.Errorf("error importing %s: %s", input.Path, err)
+		}
+		icals[i] = ical
+
+		if ical.Calendar == "" {
+			holidays = append(holidays, ical.ImportedHolidays...)
+		} else {
+			calendars[ical.Calendar] = append(calendars[ical.Calendar], ical.ImportedHolidays...)
+		}
+
+		for _, dev := range devs {
+			dev.OffDays = append(dev.OffDays, ical.ImportedOffDays[dev.Id]...)
+		}
+	}
+
 	weeks := make([]*SupportWeek, len(input.SupportWeeks))
-	for i, input := range input.SupportWeeks {
-		week, err := newSupportWeek(input)
+	for i, weekInput := range input.SupportWeeks {
+		week, err := newSupportWeek(weekInput, dates)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing week %s", err)
+			return nil, input.errorAt(weekInput, fmt.Errorf("error parsing support week of %s: %s", weekInput.DevId, err))
 		}
 		weeks[i] = week
 	}
 
-	tasks := make([]*Task, len(input.Tasks))
-	for i, input := range input.Tasks {
-		task, err := newTask(input)
+	// public holidays are added to the holidays, and keep their names. Only the ones of the year of the start day are
+	// generated here, the ones of the following years are as the forecast reaches them, see extendPublicHolidays
+	publicHolidays, err := PublicHolidays(input.HolidayRules, startDay.Year(), startDay.Year())
+	if err != nil {
+		return nil, err
+	}
+	for _, holiday := range publicHolidays {
+		holidays = append(holidays, holiday.Day)
+		holidayNames[""][holiday.Day] = holiday.Name
+	}
+
+	tasks, err := newTasks(input.Tasks, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	includes := newIncludes(input, devs, weeks, tasks)
+
+	projects := make([]*Project, len(input.Projects))
+	for i, projectInput := range input.Projects {
+		projectTasks, err := newTasks(projectInput.Tasks, dates)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing task %s", err)
+			return nil, input.errorAt(projectInput, fmt.Errorf("error in project %s: %s", projectInput.Name, err))
+		}
+		projects[i] = &Project{
+			Name:  projectInput.Name,
+			Tasks: projectTasks,
 		}
-		tasks[i] = task
 	}
 
-	startDay, err := DateToDay(input.StartDay)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing start day: %s", err)
+	// the items of the planning keep the position of their input, for CheckPlanning to report errors at
+	positions := make(map[interface{}]*sourcePosition)
+	addPosition := func(item interface{}, inputItem interface{}) {
+		if position, prs := input.positions[inputItem]; prs {
+			positions[item] = position
+		}
+	}
+	var addTaskPositions func(tasks []*Task, inputs []*TaskInput)
+	addTaskPositions = func(tasks []*Task, inputs []*TaskInput) {
+		for i, task := range tasks {
+			addPosition(task, inputs[i])
+			addTaskPositions(task.Subtasks, inputs[i].Subtasks)
+		}
+	}
+	for i, dev := range devs {
+		addPosition(dev, input.Developers[i])
+	}
+	for i, week := range weeks {
+		addPosition(week, input.SupportWeeks[i])
+	}
+	for i, ical := range icals {
+		addPosition(ical, input.ICalendars[i])
+	}
+	addTaskPositions(tasks, input.Tasks)
+	for i, project := range projects {
+		addPosition(project, input.Projects[i])
+		addTaskPositions(project.Tasks, input.Projects[i].Tasks)
 	}
 
 	return &Planning{
-		StartDay:     startDay,
-		Holidays:     holidays,
-		Developers:   devs,
-		SupportWeeks: weeks,
-		Tasks:        tasks,
+		StartDay:           startDay,
+		Holidays:           holidays,
+		Developers:         devs,
+		SupportWeeks:       weeks,
+		Tasks:              tasks,
+		Projects:           projects,
+		Roster:             input.Roster,
+		RosterPlannings:    input.RosterPlannings,
+		Includes:           includes,
+		RampUp:             input.RampUp,
+		Calendars:          calendars,
+		ICalendars:         icals,
+		HolidayRules:       input.HolidayRules,
+		HolidayNames:       holidayNames,
+		HolidayEntries:     input.Holidays,
+		CalendarEntries:    input.Calendars,
+		DateFormat:         input.DateFormat,
+		StartDayExpression: dates.expression(input.StartDay),
+		dateLayout:         outputLayout,
+		positions:          positions,
+		holidayRulesYear:   startDay.Year(),
 	}, nil
 }
 
-func newDeveloper(input *DeveloperInput) (*Developer, error) {
-	offDays := make([]Day, len(input.OffDays))
+// newIncludes finds the developers, support weeks and tasks created from the entries of each included file
+func newIncludes(input PlanningInput, devs []*Developer, weeks []*SupportWeek, tasks []*Task) []*Include {
+	devsByInput := make(map[*DeveloperInput]*Developer, len(devs))
+	for i, dev := range input.Developers {
+		devsByInput[dev] = devs[i]
+	}
+	weeksByInput := make(map[*SupportWeekInput]*SupportWeek, len(weeks))
+	for i, week := range input.SupportWeeks {
+		weeksByInput[week] = weeks[i]
+	}
+	tasksByInput := make(map[*TaskInput]*Task, len(tasks))
+	for i, task := range input.Tasks {
+		tasksByInput[task] = tasks[i]
+	}
 
-	for i, s := range input.OffDays {
-		d, err := DateToDay(s)
-		if err != nil {
-			return nil, err
+	includes := make([]*Include, len(input.Include))
+	for i, path := range input.Include {
+		include := &Include{Path: path}
+		if i < len(input.Included) {
+			included := input.Included[i]
+			include.HolidayEntries = included.Holidays
+			for _, dev := range included.Developers {
+				include.Developers = append(include.Developers, devsByInput[dev])
+			}
+			for _, week := range included.SupportWeeks {
+				include.SupportWeeks = append(include.SupportWeeks, weeksByInput[week])
+			}
+			for _, task := range included.Tasks {
+				include.Tasks = append(include.Tasks, tasksByInput[task])
+			}
 		}
-		offDays[i] = d
+		includes[i] = include
+	}
+	return includes
+}
+
+func newDeveloper(input *DeveloperInput, dates *dateParser) (*Developer, error) {
+	offDays, err := newDays(input.OffDays, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	reasons, err := newOffDayReasons(input.OffDays, dates)
+	if err != nil {
+		return nil, err
 	}
 
 	var starts *Day
+	var startsExpression string
 	if input.Starts != nil {
-		day, err := DateToDay(*input.Starts)
+		day, err := dates.parse(*input.Starts)
 		if err != nil {
 			return nil, err
 		}
 		starts = &day
+		startsExpression = dates.expression(*input.Starts)
 	}
 
 	var leaves *Day
+	var leavesExpression string
 	if input.Leaves != nil {
-		day, err := DateToDay(*input.Leaves)
+		day, err := dates.parse(*input.Leaves)
 		if err != nil {
 			return nil, err
 		}
 		leaves = &day
+		leavesExpression = dates.expression(*input.Leaves)
 	}
 
 	var utilization float64
@@ -122,57 +414,112 @@ func newDeveloper(input *DeveloperInput) (*Developer, error) {
 	} else {
 		utilization = *input.Utilization
 	}
+	if utilization <= 0 || utilization > 1 {
+		return nil, fmt.Errorf("utilization of developer %s needs to be greater than 0, and at most 1", input.Id)
+	}
 
 	return &Developer{
-		Id:      input.Id,
-		OffDays: offDays,
-		Starts:  starts,
-		Leaves:    leaves,
-		Utilization: utilization,
+		Id:               input.Id,
+		OffDays:          offDays,
+		Starts:           starts,
+		Leaves:           leaves,
+		Utilization:      utilization,
+		Allocations:      input.Allocations,
+		RampUp:           input.RampUp,
+		Calendars:        input.Calendars,
+		OffDayReasons:    reasons,
+		OffDayEntries:    input.OffDays,
+		StartsExpression: startsExpression,
+		LeavesExpression: leavesExpression,
 	}, nil
 }
 
-func newSupportWeek(input *SupportWeekInput) (*SupportWeek, error) {
-	firstDay, err := DateToDay(input.FirstDay)
+func newSupportWeek(input *SupportWeekInput, dates *dateParser) (*SupportWeek, error) {
+	firstDay, err := dates.parseField("firstDay", input.FirstDay)
 	if err != nil {
 		return nil, err
 	}
 
-	lastDay, err := DateToDay(input.LastDay)
+	lastDay, err := dates.parseField("lastDay", input.LastDay)
 	if err != nil {
 		return nil, err
 	}
 
 	return &SupportWeek{
-		FirstDay: firstDay,
-		LastDay:  lastDay,
-		DevId:    input.DevId,
+		FirstDay:           firstDay,
+		LastDay:            lastDay,
+		DevId:              input.DevId,
+		FirstDayExpression: dates.expression(input.FirstDay),
+		LastDayExpression:  dates.expression(input.LastDay),
 	}, nil
 }
 
-func newTask(input *TaskInput) (*Task, error) {
+func newTasks(inputs []*TaskInput, dates *dateParser) ([]*Task, error) {
+	tasks := make([]*Task, len(inputs))
+	for i, input := range inputs {
+		task, err := newTask(input, dates)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing task %s", err)
+		}
+		tasks[i] = task
+	}
+	return tasks, nil
+}
+
+func newTask(input *TaskInput, dates *dateParser) (*Task, error) {
 	attrs := make(map[DeveloperId]*Attribution, len(input.Attributions))
+	for _, attribution := range input.Attributions {
+		attr, err := newAttribution(attribution.Attribution, dates)
+		if err != nil {
+			return nil, fmt.Errorf("error in creating task for %+v: %s", attribution.Attribution, err)
+		}
+		attrs[attribution.DevId] = attr
+	}
+
+	var deadline *Day
+	var deadlineExpression string
+	if input.Deadline != nil {
+		day, err := dates.parse(*input.Deadline)
+		if err != nil {
+			return nil, err
+		}
+		deadline = &day
+		deadlineExpression = dates.expression(*input.Deadline)
+	}
+
+	weight := 1.0
+	if input.Weight != nil {
+		weight = *input.Weight
+	}
 
-	for devId, input := range input.Attributions {
-		attr, err := newAttribution(input)
+	subtasks := make([]*Task, len(input.Subtasks))
+	for i, input := range input.Subtasks {
+		subtask, err := newTask(input, dates)
 		if err != nil {
-			return nil, fmt.Errorf("error in creating task for %+v: %s", input, err)
+			return nil, fmt.Errorf("error in subtask %s: %s", input.Name, err)
 		}
-		attrs[devId] = attr
+		subtasks[i] = subtask
 	}
 
 	return &Task{
-		Name:         input.Name,
-		Attributions: attrs,
+		Id:                 input.Id,
+		Name:               input.Name,
+		Attributions:       attrs,
+		AttributionOrder:   input.Attributions.developerIds(),
+		Subtasks:           subtasks,
+		Deadline:           deadline,
+		Weight:             weight,
+		Pinned:             input.Pinned,
+		DeadlineExpression: deadlineExpression,
 	}, nil
 }
 
-func newAttribution(input *AttributionInput) (*Attribution, error) {
+func newAttribution(input *AttributionInput, dates *dateParser) (*Attribution, error) {
 	var firstDay *Day
 	var lastDay *Day
 
 	if input.FirstDay != nil {
-		day, err := DateToDay(*input.FirstDay)
+		day, err := dates.parse(*input.FirstDay)
 		if err != nil {
 			return nil, err
 		}
@@ -180,7 +527,7 @@ func newAttribution(input *AttributionInput) (*Attribution, error) {
 	}
 
 	if input.LastDay != nil {
-		day, err := DateToDay(*input.LastDay)
+		day, err := dates.parse(*input.LastDay)
 		if err != nil {
 			return nil, err
 		}
@@ -195,78 +542,280 @@ func newAttribution(input *AttributionInput) (*Attribution, error) {
 }
 
 func NewPlanningInput(planning *Planning) *PlanningInput {
-	holidays := make([]string, len(planning.Holidays))
-	for i, day := range planning.Holidays {
-		holidays[i] = DayToDate(day)
+	layout := planning.layout()
+
+	// imported days are written back as a reference to their iCalendar file only
+	importedHolidays := make(map[Day]bool)
+	importedCalendars := make(map[string]map[Day]bool)
+	icals := make([]*ICalendarInput, len(planning.ICalendars))
+	for i, ical := range planning.ICalendars {
+		imported := importedHolidays
+		if ical.Calendar != "" {
+			if importedCalendars[ical.Calendar] == nil {
+				importedCalendars[ical.Calendar] = make(map[Day]bool)
+			}
+			imported = importedCalendars[ical.Calendar]
+		}
+		for _, day := range ical.ImportedHolidays {
+			imported[day] = true
+		}
+
+		icals[i] = &ICalendarInput{
+			Path:       ical.Path,
+			Holidays:   ical.Holidays,
+			Calendar:   ical.Calendar,
+			Developers: ical.Developers,
+		}
 	}
 
-	developers := make([]*DeveloperInput, len(planning.Developers))
-	for i, developer := range planning.Developers {
-		offDays := make([]string, developer.OffDays.Len())
-		for j, day := range developer.OffDays {
-			offDays[j] = DayToDate(day)
+	// so are public holidays, as a reference to their rules
+	publicHolidays, _ := PublicHolidays(planning.HolidayRules, planning.StartDay.Year(), planning.holidayRulesYear)
+	for _, holiday := range publicHolidays {
+		importedHolidays[holiday.Day] = true
+	}
+
+	// entries of the included files are written back to them
+	included := planning.included()
+
+	holidays := planning.HolidayEntries
+	if holidays == nil {
+		holidays = newDaysInputs(planning.Holidays, importedHolidays, layout)
+	} else if len(included.holidays) > 0 {
+		holidays = make([]*DaysInput, 0, len(planning.HolidayEntries))
+		for _, entry := range planning.HolidayEntries {
+			if !included.holidays[entry] {
+				holidays = append(holidays, entry)
+			}
 		}
-		var starts *string
-		if developer.Starts != nil {
-			date := DayToDate(*developer.Starts)
-			starts = &date
+	}
+
+	calendars := planning.CalendarEntries
+	if calendars == nil {
+		calendars = make(map[string][]*DaysInput, len(planning.Calendars))
+		for name, days := range planning.Calendars {
+			entries := newDaysInputs(days, importedCalendars[name], layout)
+			// calendars only made of imported days are created by the import
+			if len(entries) > 0 || importedCalendars[name] == nil {
+				calendars[name] = entries
+			}
 		}
-		var leaves *string
-		if developer.Leaves != nil {
-			date := DayToDate(*developer.Leaves)
-			leaves = &date
+	}
+
+	importedOffDays := icalOffDays(planning)
+	developers := make([]*DeveloperInput, 0, len(planning.Developers))
+	for _, developer := range planning.Developers {
+		if !included.developers[developer] {
+			developers = append(developers, newDeveloperInput(developer, importedOffDays[developer.Id], layout))
 		}
+	}
 
-		developers[i] = &DeveloperInput{
-			Id:      developer.Id,
-			OffDays: offDays,
-			Starts:  starts,
-			Leaves:    leaves,
-			Utilization: &developer.Utilization,
+	supportWeeks := make([]*SupportWeekInput, 0, len(planning.SupportWeeks))
+	for _, week := range planning.SupportWeeks {
+		if !included.supportWeeks[week] {
+			supportWeeks = append(supportWeeks, newSupportWeekInput(week, layout))
 		}
 	}
 
-	supportWeeks := make([]*SupportWeekInput, len(planning.SupportWeeks))
-	for i, week := range planning.SupportWeeks {
-		supportWeeks[i] = &SupportWeekInput{
-			FirstDay: DayToDate(week.FirstDay),
-			LastDay:  DayToDate(week.LastDay),
-			DevId:    week.DevId,
+	tasks := make([]*Task, 0, len(planning.Tasks))
+	for _, task := range planning.Tasks {
+		if !included.tasks[task] {
+			tasks = append(tasks, task)
 		}
 	}
+	taskInputs := newTaskInputs(tasks, layout)
+
+	includes := make([]string, len(planning.Includes))
+	for i, include := range planning.Includes {
+		includes[i] = include.Path
+	}
 
-	tasks := make([]*TaskInput, len(planning.Tasks))
-	for i, task := range planning.Tasks {
-		attributions := make(map[DeveloperId]*AttributionInput)
-		for developerId, attribution := range task.Attributions {
-			attributions[developerId] = newAttributionInput(attribution)
+	projects := make([]*ProjectInput, len(planning.Projects))
+	for i, project := range planning.Projects {
+		projects[i] = &ProjectInput{
+			Name:  project.Name,
+			Tasks: newTaskInputs(project.Tasks, layout),
 		}
+	}
 
-		tasks[i] = &TaskInput{
-			Name:         task.Name,
-			Attributions: attributions,
+	criticalPath := make([]*CriticalStepInput, len(planning.CriticalPath))
+	for i, step := range planning.CriticalPath {
+		offDays := make([]string, len(step.OffDays))
+		for j, day := range step.OffDays {
+			offDays[j] = formatDate(layout, day)
+		}
+		criticalPath[i] = &CriticalStepInput{
+			Task:     step.Path,
+			DevId:    step.DevId,
+			FirstDay: formatDate(layout, step.FirstDay),
+			LastDay:  formatDate(layout, step.LastDay),
+			OffDays:  offDays,
+		}
+	}
+
+	// the roster is written back as a reference, the planning output does not modify it
+	if planning.Roster != "" {
+		return &PlanningInput{
+			DateFormat:   planning.DateFormat,
+			StartDay:     writeDate(layout, planning.StartDay, planning.StartDayExpression),
+			RampUp:       planning.RampUp,
+			Roster:       planning.Roster,
+			Include:      includes,
+			Tasks:        taskInputs,
+			Projects:     projects,
+			CriticalPath: criticalPath,
 		}
 	}
 
 	return &PlanningInput{
-		StartDay:     DayToDate(planning.StartDay),
+		DateFormat:   planning.DateFormat,
+		StartDay:     writeDate(layout, planning.StartDay, planning.StartDayExpression),
+		RampUp:       planning.RampUp,
+		Include:      includes,
 		Holidays:     holidays,
+		HolidayRules: planning.HolidayRules,
+		Calendars:    calendars,
+		ICalendars:   icals,
 		Developers:   developers,
 		SupportWeeks: supportWeeks,
-		Tasks:        tasks,
+		Tasks:        taskInputs,
+		Projects:     projects,
+		CriticalPath: criticalPath,
+	}
+}
+
+// NewIncludeInput creates the content of a file included by the planning
+func NewIncludeInput(planning *Planning, include *Include) *IncludeInput {
+	layout := planning.layout()
+	importedOffDays := icalOffDays(planning)
+
+	developers := make([]*DeveloperInput, len(include.Developers))
+	for i, developer := range include.Developers {
+		developers[i] = newDeveloperInput(developer, importedOffDays[developer.Id], layout)
+	}
+
+	supportWeeks := make([]*SupportWeekInput, len(include.SupportWeeks))
+	for i, week := range include.SupportWeeks {
+		supportWeeks[i] = newSupportWeekInput(week, layout)
+	}
+
+	return &IncludeInput{
+		Holidays:     include.HolidayEntries,
+		Developers:   developers,
+		SupportWeeks: supportWeeks,
+		Tasks:        newTaskInputs(include.Tasks, layout),
+	}
+}
+
+// icalOffDays returns the off days of each developer imported from iCalendar files, which are written back as
+// a reference to their iCalendar file only
+func icalOffDays(planning *Planning) map[DeveloperId]map[Day]bool {
+	importedOffDays := make(map[DeveloperId]map[Day]bool)
+	for _, ical := range planning.ICalendars {
+		for devId, days := range ical.ImportedOffDays {
+			if importedOffDays[devId] == nil {
+				importedOffDays[devId] = make(map[Day]bool)
+			}
+			for _, day := range days {
+				importedOffDays[devId][day] = true
+			}
+		}
+	}
+	return importedOffDays
+}
+
+func newDeveloperInput(developer *Developer, importedOffDays map[Day]bool, layout string) *DeveloperInput {
+	offDays := developer.OffDayEntries
+	if offDays == nil {
+		offDays = newDaysInputs(developer.OffDays, importedOffDays, layout)
+	}
+	var starts *string
+	if developer.Starts != nil {
+		date := writeDate(layout, *developer.Starts, developer.StartsExpression)
+		starts = &date
+	}
+	var leaves *string
+	if developer.Leaves != nil {
+		date := writeDate(layout, *developer.Leaves, developer.LeavesExpression)
+		leaves = &date
+	}
+
+	return &DeveloperInput{
+		Id:          developer.Id,
+		OffDays:     offDays,
+		Starts:      starts,
+		Leaves:      leaves,
+		Utilization: &developer.Utilization,
+		Allocations: developer.Allocations,
+		RampUp:      developer.RampUp,
+		Calendars:   developer.Calendars,
+	}
+}
+
+func newSupportWeekInput(week *SupportWeek, layout string) *SupportWeekInput {
+	return &SupportWeekInput{
+		FirstDay: writeDate(layout, week.FirstDay, week.FirstDayExpression),
+		LastDay:  writeDate(layout, week.LastDay, week.LastDayExpression),
+		DevId:    week.DevId,
+	}
+}
+
+func newTaskInputs(tasks []*Task, layout string) []*TaskInput {
+	inputs := make([]*TaskInput, len(tasks))
+	for i, task := range tasks {
+		var deadline *string
+		if task.Deadline != nil {
+			date := writeDate(layout, *task.Deadline, task.DeadlineExpression)
+			deadline = &date
+		}
+
+		var attributions AttributionInputs
+		for _, developerId := range task.DeveloperIds() {
+			attributions = append(attributions, &DeveloperAttributionInput{
+				DevId:       developerId,
+				Attribution: newAttributionInput(task.Attributions[developerId], layout),
+			})
+		}
+
+		var weight *float64
+		if task.Weight != 1 {
+			w := task.Weight
+			weight = &w
+		}
+
+		inputs[i] = &TaskInput{
+			Id:           task.Id,
+			Name:         task.Name,
+			Attributions: attributions,
+			Subtasks:     newTaskInputs(task.Subtasks, layout),
+			FirstDay:     dayToOptionalDate(task.FirstDay, layout),
+			LastDay:      dayToOptionalDate(task.LastDay, layout),
+			Deadline:     deadline,
+			Weight:       weight,
+			Pinned:       task.Pinned,
+			Slack:        task.Slack,
+		}
+	}
+	return inputs
+}
+
+func dayToOptionalDate(day *Day, layout string) *string {
+	if day == nil {
+		return nil
 	}
+	date := formatDate(layout, *day)
+	return &date
 }
 
-func newAttributionInput(attr *Attribution) *AttributionInput {
+func newAttributionInput(attr *Attribution, layout string) *AttributionInput {
 	var firstDay *string
 	if attr.FirstDay != nil {
-		date := DayToDate(*attr.FirstDay)
+		date := formatDate(layout, *attr.FirstDay)
 		firstDay = &date
 	}
 
 	var lastDay *string
 	if attr.LastDay != nil {
-		date := DayToDate(*attr.LastDay)
+		date := formatDate(layout, *attr.LastDay)
 		lastDay = &date
 	}
 
@@ -274,5 +823,7 @@ func newAttributionInput(attr *Attribution) *AttributionInput {
 		Effort:   attr.EffortDays,
 		FirstDay: firstDay,
 		LastDay:  lastDay,
+		Slack:    attr.Slack,
+		Critical: attr.Critical,
 	}
 }
@@ -2,7 +2,9 @@ package planner
 
 import (
 	"fmt"
-	"math"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,124 @@ type Planning struct {
 	SupportWeeks []*SupportWeek `yaml:"supportWeeks"`
 	// tasks are sorted in priority order: highest priority first
 	Tasks []*Task `yaml:"tasks"`
+	// projects are scheduled alongside the tasks above, each with its own priority order
+	Projects []*Project
+	// path of the roster file the developers, holidays and support weeks were read from, if any
+	Roster string
+	// planning files the roster lists as sharing it, see ReadRosterPlannings
+	RosterPlannings []string
+	// files the developers, holidays, support weeks and tasks above were partly read from
+	Includes []*Include
+	// chain of attributions that decides the last day of the planning, computed by AnalyzeCriticalPath
+	CriticalPath []*CriticalStep
+	// ramp-up of the developers who have a start day and no ramp-up of their own
+	RampUp []RampUpStep
+	// named holiday calendars, that apply only to the developers who use them, on top of the holidays
+	Calendars map[string]Days
+	// iCalendar files the holidays, calendars and off days above were partly imported from
+	ICalendars []*ICalendar
+	// countries or regions whose public holidays were added to the holidays, see PublicHolidays
+	HolidayRules []string
+	// names of the days, when they have one, by calendar: the holidays are under the empty name, and the days of each
+	// calendar under its name, so that calendars can name the same day differently. See HolidayName.
+	HolidayNames map[string]map[Day]string
+	// the entries of the input the holidays and calendars were expanded from, written back by NewPlanningInput.
+	// When nil, the days are written back as ranges.
+	HolidayEntries  []*DaysInput
+	CalendarEntries map[string][]*DaysInput
+	// format of the dates of the input, such as MM/dd/yyyy, when it set one
+	DateFormat string
+	// relative date the start day was resolved from, such as today, written back instead of the start day
+	StartDayExpression string
+	// layout the dates are written back with by NewPlanningInput, the format of the input dates by default
+	dateLayout string
+	// positions of the developers, support weeks, tasks and projects in the files they were read from, if known
+	positions map[interface{}]*sourcePosition
+	// last year the public holidays of the holiday rules were added to the holidays for, see extendPublicHolidays
+	holidayRulesYear int
+}
+
+// Include is a file a planning reads part of its developers, holidays, support weeks and tasks from.
+// Its developers, support weeks and tasks are the ones of the planning, and its holidays are entries of the planning's.
+type Include struct {
+	// path of the file, relative to the planning file
+	Path           string
+	Developers     []*Developer
+	HolidayEntries []*DaysInput
+	SupportWeeks   []*SupportWeek
+	Tasks          []*Task
+}
+
+// includedItems are the developers, holiday entries, support weeks and tasks of a planning read from included files
+type includedItems struct {
+	developers   map[*Developer]bool
+	holidays     map[*DaysInput]bool
+	supportWeeks map[*SupportWeek]bool
+	tasks        map[*Task]bool
+}
+
+func (planning *Planning) included() *includedItems {
+	included := &includedItems{
+		developers:   make(map[*Developer]bool),
+		holidays:     make(map[*DaysInput]bool),
+		supportWeeks: make(map[*SupportWeek]bool),
+		tasks:        make(map[*Task]bool),
+	}
+	for _, include := range planning.Includes {
+		for _, developer := range include.Developers {
+			included.developers[developer] = true
+		}
+		for _, entry := range include.HolidayEntries {
+			included.holidays[entry] = true
+		}
+		for _, week := range include.SupportWeeks {
+			included.supportWeeks[week] = true
+		}
+		for _, task := range include.Tasks {
+			included.tasks[task] = true
+		}
+	}
+	return included
+}
+
+// HolidayName returns the name of a day of the holidays, when the calendar is empty, or of the given calendar
+func (planning *Planning) HolidayName(calendar string, day Day) (string, bool) {
+	name, prs := planning.HolidayNames[calendar][day]
+	return name, prs
+}
+
+// layout returns the layout the dates of the planning are written with
+func (planning *Planning) layout() string {
+	if planning.dateLayout == "" {
+		return dateFormat
+	}
+	return planning.dateLayout
+}
+
+// ParseDate parses a date in the format of the planning, in the ISO 8601 format, or a relative date
+func (planning *Planning) ParseDate(str string) (Day, error) {
+	layout := dateFormat
+	if planning.DateFormat != "" {
+		var err error
+		layout, err = DateFormatLayout(planning.DateFormat)
+		if err != nil {
+			return 0, err
+		}
+	}
+	dates := newDateParser(layout)
+	dates.startDay = &planning.StartDay
+	return dates.parse(str)
+}
+
+// FormatDate writes the day in the format of the dates of the planning input
+func (planning *Planning) FormatDate(day Day) string {
+	return formatDate(planning.layout(), day)
+}
+
+type Project struct {
+	Name string
+	// tasks are sorted in priority order: highest priority first
+	Tasks []*Task
 }
 
 type DeveloperId string
@@ -35,15 +155,70 @@ func (d Days) Swap(i, j int) {
 }
 
 type Task struct {
+	// optional stable identifier, unique among all the tasks of the planning. Tasks are identified by name without it.
+	Id           string
 	Name         string
 	Attributions map[DeveloperId]*Attribution
-	LastDay      *Day
+	// developers of the attributions, in the order of the planning file
+	AttributionOrder []DeveloperId
+	// subtasks are sorted in priority order, and are scheduled after the task's own attributions
+	Subtasks []*Task
+	FirstDay *Day
+	LastDay  *Day
+	// day the task should be completed by, used to optimize the priority order
+	Deadline *Day
+	// business value of the task. Lateness of tasks with a higher weight costs more
+	Weight float64
+	// pinned tasks keep their priority when the priority order is optimized
+	Pinned bool
+	// relative date the deadline was resolved from, if any
+	DeadlineExpression string
+	// number of working days the task can slip without delaying the planning, computed by AnalyzeCriticalPath
+	Slack *int
+}
+
+// DeveloperIds returns the developers of the attributions of the task, in the order of the planning file, so that
+// outputs and charts are the same from one run to the next
+func (task *Task) DeveloperIds() []DeveloperId {
+	devIds := make([]DeveloperId, 0, len(task.Attributions))
+	for devId := range task.Attributions {
+		devIds = append(devIds, devId)
+	}
+	return orderDeveloperIds(devIds, task.AttributionOrder)
+}
+
+// orderDeveloperIds sorts developer ids in the given order. Developers missing from the order, such as the ones of
+// files whose format does not keep the order of keys, come last, sorted by id.
+func orderDeveloperIds(devIds []DeveloperId, order []DeveloperId) []DeveloperId {
+	ranks := make(map[DeveloperId]int, len(order))
+	for i, devId := range order {
+		if _, prs := ranks[devId]; !prs {
+			ranks[devId] = i
+		}
+	}
+	sort.Slice(devIds, func(i, j int) bool {
+		rankI, prsI := ranks[devIds[i]]
+		rankJ, prsJ := ranks[devIds[j]]
+		switch {
+		case prsI && prsJ:
+			return rankI < rankJ
+		case prsI != prsJ:
+			return prsI
+		default:
+			return devIds[i] < devIds[j]
+		}
+	})
+	return devIds
 }
 
 type Attribution struct {
 	EffortDays EffortDays
 	FirstDay   *Day
 	LastDay    *Day
+	// number of working days the attribution can slip without delaying the planning, computed by AnalyzeCriticalPath
+	Slack *int
+	// whether the attribution is on the critical path
+	Critical bool
 }
 
 type Developer struct {
@@ -52,75 +227,245 @@ type Developer struct {
 	Starts      *Day    `yaml:"starts"`
 	Leaves      *Day    `yaml:"leaves"`
 	Utilization float64 `yaml:"utilization"`
+	// share of the developer's capacity given to each project. A developer without allocations
+	// gives all their capacity to the only project they work on.
+	Allocations map[string]float64
+	// utilization during the first weeks after the developer starts, on top of their utilization
+	RampUp []RampUpStep
+	// names of the holiday calendars of the planning that apply to the developer, on top of its holidays
+	Calendars []string
+	// names and types of the off days, when they have them
+	OffDayReasons map[Day]OffDayReason
+	// the entries of the input the off days were expanded from, written back by NewPlanningInput.
+	// When nil, the off days are written back as ranges.
+	OffDayEntries []*DaysInput
+	// relative dates the start and leave days were resolved from, if any
+	StartsExpression string
+	LeavesExpression string
+}
+
+// RampUpStep is a period of the onboarding of a new developer, during which they work at a reduced utilization
+type RampUpStep struct {
+	Weeks       int     `yaml:"weeks" json:"weeks" toml:"weeks" description:"Number of weeks the step lasts." schema:"required"`
+	Utilization float64 `yaml:"utilization" json:"utilization" toml:"utilization" description:"Part of the utilization of the developer during the step, greater than 0 and at most 1." schema:"required"`
+}
+
+// effortEpsilon absorbs the rounding errors when effort is accumulated by fractions of days
+const effortEpsilon = 1e-9
+
+// maxAttributionDays bounds the days an attribution is scheduled over, so that the forecast ends even when a
+// developer does not work at all
+const maxAttributionDays = 100 * 366
+
+// utilizationOn returns the utilization of the developer on the given day. During the weeks following their start,
+// it is reduced by their ramp-up, or by the default ramp-up of the planning if they have none.
+func (developer *Developer) utilizationOn(day Day, defaultRampUp []RampUpStep) float64 {
+	rampUp := developer.RampUp
+	if rampUp == nil {
+		rampUp = defaultRampUp
+	}
+	if developer.Starts == nil || day < *developer.Starts {
+		return developer.Utilization
+	}
+
+	week := int(day-*developer.Starts) / 7
+	for _, step := range rampUp {
+		if week < step.Weeks {
+			return developer.Utilization * step.Utilization
+		}
+		week -= step.Weeks
+	}
+	return developer.Utilization
+}
+
+// projectUtilization returns the utilization of the developer for the given project on any day
+func (developer *Developer) projectUtilization(project string, defaultRampUp []RampUpStep) func(Day) float64 {
+	allocation := developer.allocation(project)
+	return func(day Day) float64 {
+		return developer.utilizationOn(day, defaultRampUp) * allocation
+	}
+}
+
+// allocation returns the share of the developer's capacity that goes to the given project
+func (developer *Developer) allocation(project string) float64 {
+	if allocation, prs := developer.Allocations[project]; prs {
+		return allocation
+	}
+	return 1
 }
 
 type SupportWeek struct {
 	FirstDay Day
 	LastDay  Day
 	DevId    DeveloperId `yaml:"devId"`
+	// relative dates the first and last days were resolved from, if any
+	FirstDayExpression string
+	LastDayExpression  string
 }
 
+// CheckPlanning checks the consistency of a planning, and returns all its inconsistencies at once, as SourceErrors at
+// the position of the items they are about when the planning was read from files
 func CheckPlanning(planning *Planning) error {
+	return checkPlanning(planning, true)
+}
+
+// checkPlanning checks the planning, and the developers its items refer to when references is true
+func checkPlanning(planning *Planning, references bool) error {
 	devMap := make(map[DeveloperId]*Developer, len(planning.Developers))
 	for _, dev := range planning.Developers {
 		devMap[dev.Id] = dev
 	}
 
-	holidaysMap := make(map[Day]interface{}, len(planning.Holidays))
-	for _, day := range planning.Holidays {
-		holidaysMap[day] = nil
+	// all the inconsistencies are reported at once
+	errs := &planningErrors{positions: planning.positions, references: references}
+
+	devDuplicates, devFirsts := duplicateDevelopers(planning.Developers)
+	for _, duplicate := range devDuplicates {
+		if position, prs := planning.positions[devFirsts[duplicate.Id]]; prs {
+			errs.add(duplicate, "developer %s is already defined in %s", duplicate.Id, position)
+		} else {
+			errs.add(duplicate, "developer %s is defined more than once", duplicate.Id)
+		}
+	}
+	taskDuplicates, taskFirsts := duplicateTasks(planning)
+	for _, duplicate := range taskDuplicates {
+		if position, prs := planning.positions[taskFirsts[duplicate.Id]]; prs {
+			errs.add(duplicate, "task id %s is already used in %s", duplicate.Id, position)
+		} else {
+			errs.add(duplicate, "task id %s is used by more than one task", duplicate.Id)
+		}
 	}
 
-	err := checkTasks(planning.Tasks, devMap, holidaysMap, planning.SupportWeeks)
-	if err != nil {
-		return err
+	checkTasks(planning.Tasks, devMap, "", errs)
+
+	projectNames := make(map[string]bool, len(planning.Projects))
+	for _, project := range planning.Projects {
+		if project.Name == "" {
+			errs.add(project, "projects need to have a name")
+		} else if projectNames[project.Name] {
+			errs.add(project, "project %s is defined more than once", project.Name)
+		}
+		projectNames[project.Name] = true
+
+		checkTasks(project.Tasks, devMap, fmt.Sprintf("in project %s: ", project.Name), errs)
+	}
+
+	checkSupportWeeks(planning, devMap, errs)
+	checkAllocations(planning, errs)
+
+	for _, developer := range planning.Developers {
+		for _, calendar := range developer.Calendars {
+			if _, prs := planning.Calendars[calendar]; !prs {
+				errs.add(developer, "calendar %s of developer %s does not exist", calendar, developer.Id)
+			}
+		}
 	}
 
-	err = checkSupportWeeks(planning.SupportWeeks, devMap)
+	for _, ical := range planning.ICalendars {
+		for devId := range ical.Developers {
+			if _, prs := devMap[devId]; !prs && errs.references {
+				errs.add(ical, "developer %s mentioned in iCalendar %s does not exist%s", devId, ical.Path, suggestDeveloper(devId, devMap))
+			}
+		}
+	}
+
+	err := checkRampUp(planning.RampUp)
 	if err != nil {
-		return err
+		errs.add(nil, "invalid ramp-up of the planning: %s", err)
+	}
+	for _, developer := range planning.Developers {
+		err = checkRampUp(developer.RampUp)
+		if err != nil {
+			errs.add(developer, "invalid ramp-up of developer %s: %s", developer.Id, err)
+		}
 	}
 
+	if len(errs.errors) > 0 {
+		return errs.errors
+	}
 	return nil
 }
 
-// ForecastCompletion attributes a FirstDay and a LastDay to all attributions,
-// as well as a last day to all tasks
-func ForecastCompletion(planning *Planning) {
-	// This maps developers to all their non-worked days
-	devToOffDays := make(map[DeveloperId]map[Day]bool)
+// planningErrors collects the inconsistencies of a planning, at the position of the items they are about when known
+type planningErrors struct {
+	positions map[interface{}]*sourcePosition
+	// whether the developers the items refer to are checked
+	references bool
+	errors     SourceErrors
+}
 
-	// fill the map with empty maps
-	for _, developer := range planning.Developers {
-		devToOffDays[developer.Id] = make(map[Day]bool)
+func (errs *planningErrors) add(item interface{}, format string, args ...interface{}) {
+	if position, prs := errs.positions[item]; prs {
+		errs.errors = append(errs.errors, position.errorf(format, args...))
+		return
 	}
+	errs.errors = append(errs.errors, &SourceError{Message: fmt.Sprintf(format, args...)})
+}
 
-	// holidays
-	for _, holiday := range planning.Holidays {
-		for _, developer := range planning.Developers {
-			devToOffDays[developer.Id][holiday] = true
+// duplicateDevelopers returns the developers whose id is the one of a previous developer, in order, along with the
+// first developer of each id
+func duplicateDevelopers(developers []*Developer) ([]*Developer, map[DeveloperId]*Developer) {
+	firsts := make(map[DeveloperId]*Developer)
+	duplicates := make([]*Developer, 0)
+	for _, developer := range developers {
+		if _, prs := firsts[developer.Id]; prs {
+			duplicates = append(duplicates, developer)
+		} else {
+			firsts[developer.Id] = developer
 		}
 	}
+	return duplicates, firsts
+}
 
-	// off days
-	for _, developer := range planning.Developers {
-		for _, day := range developer.OffDays {
-			devToOffDays[developer.Id][day] = true
+// duplicateTasks returns the tasks of the planning, subtasks and projects included, whose id is the one of a previous
+// task, in order, along with the first task of each id
+func duplicateTasks(planning *Planning) ([]*Task, map[string]*Task) {
+	firsts := make(map[string]*Task)
+	duplicates := make([]*Task, 0)
+	walkTaskPaths(planning, func(project string, path string, task *Task) {
+		if task.Id == "" {
+			return
+		}
+		if _, prs := firsts[task.Id]; prs {
+			duplicates = append(duplicates, task)
+		} else {
+			firsts[task.Id] = task
 		}
+	})
+	return duplicates, firsts
+}
+
+// suggestDeveloper suggests the id of an existing developer for a misspelled one
+func suggestDeveloper(devId DeveloperId, devMap map[DeveloperId]*Developer) string {
+	ids := make([]string, 0, len(devMap))
+	for id := range devMap {
+		ids = append(ids, string(id))
 	}
+	sort.Strings(ids)
+	return didYouMean(string(devId), ids)
+}
 
-	// support weeks
-	for _, week := range planning.SupportWeeks {
-		for i := week.FirstDay; i <= week.LastDay; i++ {
-			devToOffDays[week.DevId][i] = true
+// ForecastCompletion attributes a FirstDay and a LastDay to all attributions,
+// as well as a last day to all tasks
+func ForecastCompletion(planning *Planning) {
+	forecastCompletion(planning)
+
+	// public holidays are generated up to the year of the last planned day, and the forecast is run again when it
+	// reached a year they were not generated for yet
+	for {
+		var lastDay *Day
+		walkAttributions(planning, func(_ string, _ *Task, _ DeveloperId, attribution *Attribution) {
+			lastDay = maxDay(lastDay, attribution.LastDay)
+		})
+		if lastDay == nil || !planning.extendPublicHolidays(*lastDay) {
+			return
 		}
+		forecastCompletion(planning)
 	}
+}
 
-	devToUtilization := make(map[DeveloperId]float64)
-
-	for _, developer := range planning.Developers {
-		devToUtilization[developer.Id] = developer.Utilization
-	}
+func forecastCompletion(planning *Planning) {
+	calendar := NewCalendar(planning)
 
 	// devToLatestDay associate a the latest day that was allocated for each developer
 	// as we go through each task and each attribution by order of priority, we are going to increment this day
@@ -135,20 +480,50 @@ func ForecastCompletion(planning *Planning) {
 		}
 	}
 
-	for _, task := range planning.Tasks {
+	// each project gets its own share of the developers' capacity, so projects are scheduled independently
+	// from the same starting point
+	forecastProject := func(project string, tasks []*Task) {
+		projectUtilization := make(map[DeveloperId]func(Day) float64, len(planning.Developers))
+		projectLatestDay := make(map[DeveloperId]Day, len(planning.Developers))
+		for _, developer := range planning.Developers {
+			projectUtilization[developer.Id] = developer.projectUtilization(project, planning.RampUp)
+			projectLatestDay[developer.Id] = devToLatestDay[developer.Id]
+		}
+		forecastTasks(tasks, calendar, projectUtilization, projectLatestDay)
+	}
+
+	forecastProject("", planning.Tasks)
+	for _, project := range planning.Projects {
+		forecastProject(project.Name, project.Tasks)
+	}
+}
+
+// forecastTasks schedules the tasks in tree order: a task's own attributions first, then its subtasks.
+// The first and last days of a task span those of its attributions and subtasks.
+func forecastTasks(tasks []*Task, calendar *Calendar, devToUtilization map[DeveloperId]func(Day) float64, devToLatestDay map[DeveloperId]Day) {
+	for _, task := range tasks {
+		var firstTaskDay *Day
 		var lastTaskDay *Day
+		// a task is not completed when one of its attributions or subtasks is not
+		completed := true
+		task.FirstDay = nil
 		task.LastDay = nil
-		for developerId, attribution := range task.Attributions {
+		for _, developerId := range task.DeveloperIds() {
+			attribution := task.Attributions[developerId]
 			attribution.FirstDay = nil
 			attribution.LastDay = nil
-			var effort EffortDays = 0
+			effort := 0.0
 			utilization := devToUtilization[developerId]
-			duration := int64(math.Ceil(float64(attribution.EffortDays) / utilization))
-			for int64(effort) < duration {
+			earliestDay := devToLatestDay[developerId]
+			for effort < float64(attribution.EffortDays)-effortEpsilon {
 				day := devToLatestDay[developerId]
-				// if the day is not off, increment the effort
-				if _, prs := devToOffDays[developerId][day]; !prs && !isWeekEnd(day) {
-					effort++
+				// the attribution stays unplanned when the developer leaves before completing it
+				if calendar.hasLeft(developerId, day) || day-earliestDay > maxAttributionDays {
+					break
+				}
+				// if the day is not off, increment the effort by the part of the day worked on features
+				if calendar.IsWorkingDay(developerId, day) {
+					effort += utilization(day)
 					// if the first day is not set, set it
 					if attribution.FirstDay == nil {
 						firstDay := devToLatestDay[developerId]
@@ -158,81 +533,258 @@ func ForecastCompletion(planning *Planning) {
 
 				devToLatestDay[developerId] = day + 1
 			}
+			if effort < float64(attribution.EffortDays)-effortEpsilon {
+				attribution.FirstDay = nil
+				completed = false
+				continue
+			}
 			attrLastDay := devToLatestDay[developerId] - 1
 			attribution.LastDay = &attrLastDay
 
-			if lastTaskDay == nil || attrLastDay > *lastTaskDay {
-				lastTaskDay = &attrLastDay
-			}
+			firstTaskDay = minDay(firstTaskDay, attribution.FirstDay)
+			lastTaskDay = maxDay(lastTaskDay, attribution.LastDay)
 		}
-		task.LastDay = lastTaskDay
+
+		forecastTasks(task.Subtasks, calendar, devToUtilization, devToLatestDay)
+		for _, subtask := range task.Subtasks {
+			firstTaskDay = minDay(firstTaskDay, subtask.FirstDay)
+			lastTaskDay = maxDay(lastTaskDay, subtask.LastDay)
+			completed = completed && subtask.LastDay != nil
+		}
+
+		task.FirstDay = firstTaskDay
+		if completed {
+			task.LastDay = lastTaskDay
+		}
+	}
+}
+
+func minDay(a *Day, b *Day) *Day {
+	if a == nil || (b != nil && *b < *a) {
+		return b
 	}
+	return a
+}
+
+func maxDay(a *Day, b *Day) *Day {
+	if a == nil || (b != nil && *b > *a) {
+		return b
+	}
+	return a
 }
 
 func isWeekEnd(day Day) bool {
-	weekDay := DayToTime(day).Weekday()
+	weekDay := day.Weekday()
 	return weekDay == time.Saturday || weekDay == time.Sunday
 }
 
 // check devs in support weeks exist
 // check support weeks are not overlapping, and that weeks are not empty
-func checkSupportWeeks(supportWeeks []*SupportWeek, devMap map[DeveloperId]*Developer) error {
-	if len(supportWeeks) == 0 {
-		return nil
+func checkSupportWeeks(planning *Planning, devMap map[DeveloperId]*Developer, errs *planningErrors) {
+	weekOfDay := make(map[Day]*SupportWeek)
+	for _, week := range planning.SupportWeeks {
+		if _, prs := devMap[week.DevId]; !prs && errs.references {
+			errs.add(week, "developer %s mentioned in support week of %s does not exist%s", week.DevId, planning.FormatDate(week.FirstDay), suggestDeveloper(week.DevId, devMap))
+		}
+
+		if week.LastDay < week.FirstDay {
+			errs.add(week, "support week of %s from %s to %s is empty", week.DevId, planning.FormatDate(week.FirstDay), planning.FormatDate(week.LastDay))
+			continue
+		}
+		for day := week.FirstDay; day <= week.LastDay; day++ {
+			if other, prs := weekOfDay[day]; prs {
+				errs.add(week, "day %s is in the support weeks of both %s and %s", planning.FormatDate(day), other.DevId, week.DevId)
+				break
+			}
+		}
+		for day := week.FirstDay; day <= week.LastDay; day++ {
+			if _, prs := weekOfDay[day]; !prs {
+				weekOfDay[day] = week
+			}
+		}
 	}
+}
 
-	minWeek := Day(1e6)
-	maxWeek := Day(0)
-	for _, week := range supportWeeks {
-		if week.FirstDay < minWeek {
-			minWeek = week.FirstDay
+// developerProjects returns the projects each developer is attributed tasks in, in order. Tasks outside of any project
+// count as an unnamed project.
+func developerProjects(planning *Planning) map[DeveloperId][]string {
+	devToProjects := make(map[DeveloperId][]string)
+	addProject := func(project string, tasks []*Task) {
+		for devId := range tasksDevelopers(tasks) {
+			devToProjects[devId] = append(devToProjects[devId], project)
 		}
+	}
+	addProject("", planning.Tasks)
+	for _, project := range planning.Projects {
+		addProject(project.Name, project.Tasks)
+	}
+	return devToProjects
+}
 
-		if week.LastDay > maxWeek {
-			maxWeek = week.LastDay
+// check allocations are within the developers' capacity, and that developers shared between projects
+// have an explicit allocation for each of them. Tasks outside of any project count as an unnamed project.
+func checkAllocations(planning *Planning, errs *planningErrors) {
+	devToProjects := developerProjects(planning)
+
+	for _, developer := range planning.Developers {
+		total := 0.0
+		projects := make([]string, 0, len(developer.Allocations))
+		for project := range developer.Allocations {
+			projects = append(projects, project)
+		}
+		sort.Strings(projects)
+		for _, project := range projects {
+			allocation := developer.Allocations[project]
+			if allocation <= 0 {
+				errs.add(developer, "allocation of developer %s to project %s needs to be positive", developer.Id, project)
+			}
+			total += allocation
+		}
+		if total > 1 {
+			errs.add(developer, "allocations of developer %s add up to %v, more than their whole capacity", developer.Id, total)
 		}
 
-		if _, prs := devMap[week.DevId]; !prs {
-			return fmt.Errorf("developer %s mentioned in support week %v does not exit", week.DevId, week)
+		projects = devToProjects[developer.Id]
+		if len(developer.Allocations) == 0 {
+			if len(projects) > 1 {
+				errs.add(developer, "developer %s is shared between projects %v and needs an allocation for each of them", developer.Id, projects)
+			}
+			continue
+		}
+		for _, project := range projects {
+			if _, prs := developer.Allocations[project]; !prs {
+				if project == "" {
+					errs.add(developer, "developer %s has allocations, and cannot be attributed tasks outside of a project", developer.Id)
+				} else {
+					errs.add(developer, "developer %s has no allocation for project %s", developer.Id, project)
+				}
+			}
 		}
 	}
+}
 
-	allDays := make([]bool, maxWeek-minWeek+1)
-	for _, week := range supportWeeks {
-		isEmpty := true
-		for i := week.FirstDay; i < week.LastDay+1; i++ {
-			isEmpty = false
-			if allDays[i-minWeek] {
-				return fmt.Errorf("day %d is in more than one week", i)
+// CheckSharedRoster checks that the plannings sharing a roster file do not book its developers for more than their
+// whole capacity, as each of them is forecast on its own. A planning books the allocations of the projects it
+// attributes a developer tasks in, or their whole capacity when they have no allocations. The plannings are keyed by
+// the path of their file.
+func CheckSharedRoster(plannings map[string]*Planning) error {
+	paths := make([]string, 0, len(plannings))
+	for path := range plannings {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// the plannings of each roster, and the share of the capacity of each developer they book
+	rosters := make([]string, 0)
+	rosterDevelopers := make(map[string][]*Developer)
+	bookings := make(map[string]map[DeveloperId]float64)
+	bookers := make(map[string]map[DeveloperId][]string)
+	for _, path := range paths {
+		planning := plannings[path]
+		if planning.Roster == "" {
+			continue
+		}
+		roster := planning.Roster
+		if !filepath.IsAbs(roster) {
+			roster = filepath.Join(filepath.Dir(path), roster)
+		}
+		roster = filepath.Clean(roster)
+		if _, prs := bookings[roster]; !prs {
+			rosters = append(rosters, roster)
+			rosterDevelopers[roster] = planning.Developers
+			bookings[roster] = make(map[DeveloperId]float64)
+			bookers[roster] = make(map[DeveloperId][]string)
+		}
+
+		for devId, share := range bookedShares(planning) {
+			bookings[roster][devId] += share
+			bookers[roster][devId] = append(bookers[roster][devId], path)
+		}
+	}
+
+	errs := make(SourceErrors, 0)
+	for _, roster := range rosters {
+		for _, developer := range rosterDevelopers[roster] {
+			if len(bookers[roster][developer.Id]) > 1 && bookings[roster][developer.Id] > 1+effortEpsilon {
+				errs = append(errs, &SourceError{
+					Path: roster,
+					Message: fmt.Sprintf("developer %s is booked for %v of their capacity by plannings %s", developer.Id,
+						bookings[roster][developer.Id], strings.Join(bookers[roster][developer.Id], ", ")),
+				})
 			}
-			allDays[i-minWeek] = true
 		}
-		if isEmpty {
-			return fmt.Errorf("support week %v is empty", week)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bookedShares returns the share of the capacity of each developer the planning attributes tasks to: the allocations
+// of the projects of their tasks, or their whole capacity when they have no allocations
+func bookedShares(planning *Planning) map[DeveloperId]float64 {
+	devToProjects := developerProjects(planning)
+	shares := make(map[DeveloperId]float64)
+	for _, developer := range planning.Developers {
+		projects := devToProjects[developer.Id]
+		if len(projects) == 0 {
+			continue
+		}
+		if len(developer.Allocations) == 0 {
+			shares[developer.Id] = 1
+			continue
+		}
+		for _, project := range projects {
+			shares[developer.Id] += developer.Allocations[project]
+		}
+	}
+	return shares
+}
+
+func checkRampUp(rampUp []RampUpStep) error {
+	for _, step := range rampUp {
+		if step.Weeks <= 0 {
+			return fmt.Errorf("step %+v needs to last at least one week", step)
+		}
+		if step.Utilization <= 0 || step.Utilization > 1 {
+			return fmt.Errorf("step %+v needs to have a utilization greater than 0, and at most 1", step)
 		}
 	}
 	return nil
 }
 
-func checkTasks(tasks []*Task, devMap map[DeveloperId]*Developer, holidaysMap map[Day]interface{}, supportWeeks []*SupportWeek) error {
+// tasksDevelopers returns the developers attributed to the tasks or any of their subtasks
+func tasksDevelopers(tasks []*Task) map[DeveloperId]bool {
+	devs := make(map[DeveloperId]bool)
+	for _, task := range tasks {
+		for devId := range task.Attributions {
+			devs[devId] = true
+		}
+		for devId := range tasksDevelopers(task.Subtasks) {
+			devs[devId] = true
+		}
+	}
+	return devs
+}
+
+// checkTasks checks the tasks and their subtasks, whose errors start with the prefix
+func checkTasks(tasks []*Task, devMap map[DeveloperId]*Developer, prefix string, errs *planningErrors) {
 	for _, t := range tasks {
-		if len(t.Attributions) == 0 {
-			return fmt.Errorf("task %s needs to have at least one attribution", t.Name)
+		if len(t.Attributions) == 0 && len(t.Subtasks) == 0 {
+			errs.add(t, "%stask %s needs to have at least one attribution or subtask", prefix, t.Name)
 		}
 
-		for devId := range t.Attributions {
-			_, devPrs := devMap[devId]
-			if !devPrs {
-				return fmt.Errorf("developer %s mentioned in Task %v does not exist", devId, t)
-			}
+		if t.Weight < 0 {
+			errs.add(t, "%stask %s needs to have a positive weight", prefix, t.Name)
+		}
 
-			devSupportWeeks := make([]*SupportWeek, 0)
-			for _, w := range supportWeeks {
-				if w.DevId == devId {
-					devSupportWeeks = append(devSupportWeeks, w)
-				}
+		for _, devId := range t.DeveloperIds() {
+			if _, prs := devMap[devId]; !prs && errs.references {
+				errs.add(t, "%sdeveloper %s mentioned in task %s does not exist%s", prefix, devId, t.Name, suggestDeveloper(devId, devMap))
 			}
 		}
+
+		checkTasks(t.Subtasks, devMap, prefix, errs)
 	}
-	return nil
 }
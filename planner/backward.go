@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ostapneko/planner"
+	"github.com/urfave/cli/v2"
+	"log"
+)
+
+var backwardCommand = &cli.Command{
+	Name:      "backward",
+	Usage:     "compute the day each attribution of a task must start by, for the task to be completed by a target day",
+	ArgsUsage: "planning",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "task",
+			Aliases:  []string{"t"},
+			Usage:    "path of the task: its project, parents and name, separated by \" / \", or its id preceded by #",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "by",
+			Usage: "target day of completion, defaults to the deadline of the task",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			log.Fatalf("Require the input planning as argument")
+		}
+
+		planning := forecast(c.Args().Get(0))
+
+		path := c.String("task")
+		task, _, err := planner.FindTask(planning, path)
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		var target planner.Day
+		if c.IsSet("by") {
+			target, err = planning.ParseDate(c.String("by"))
+			if err != nil {
+				log.Fatalf("invalid target day: %s", err)
+			}
+		} else if task.Deadline != nil {
+			target = *task.Deadline
+		} else {
+			log.Fatalf("task %s has no deadline, a target day is required", path)
+		}
+
+		starts, err := planner.ScheduleBackward(planning, path, target)
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		for _, start := range starts {
+			line := fmt.Sprintf("%s (%s): must start by %s", start.Path, start.DevId, planning.FormatDate(start.FirstDay))
+
+			// compare with the forecast, to show the margin left
+			if forecasted := start.Attribution.FirstDay; forecasted != nil {
+				line += fmt.Sprintf(", forecasted to start on %s (%+d days of margin)",
+					planning.FormatDate(*forecasted), start.FirstDay-*forecasted)
+			}
+
+			if start.FirstDay < planning.StartDay {
+				line += ", before the start of the planning"
+			}
+			if start.BeforeStart {
+				line += fmt.Sprintf(", before %s starts: the task cannot be completed in time", start.DevId)
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
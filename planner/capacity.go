@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ostapneko/planner"
+	"github.com/urfave/cli/v2"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+var capacityCommand = &cli.Command{
+	Name:      "capacity",
+	Usage:     "show the available and allocated days of each developer for each ISO week",
+	ArgsUsage: "planning",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "first day of the report, defaults to the start day of the planning",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "last day of the report, defaults to the last planned day",
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "table, csv or json",
+			Value:   "table",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			log.Fatalf("Require the input planning as argument")
+		}
+
+		planning := forecast(c.Args().Get(0))
+
+		from := planning.StartDay
+		if c.IsSet("from") {
+			day, err := planning.ParseDate(c.String("from"))
+			if err != nil {
+				log.Fatalf("invalid from day: %s", err)
+			}
+			from = day
+		}
+
+		to := from
+		if lastDay := planner.LastPlannedDay(planning); lastDay != nil {
+			to = *lastDay
+		}
+		if c.IsSet("to") {
+			day, err := planning.ParseDate(c.String("to"))
+			if err != nil {
+				log.Fatalf("invalid to day: %s", err)
+			}
+			to = day
+		}
+
+		capacities := planner.WeeklyCapacity(planning, from, to)
+
+		var err error
+		switch format := c.String("format"); format {
+		case "table":
+			err = writeCapacityTable(capacities, os.Stdout)
+		case "csv":
+			err = writeCapacityCSV(capacities, os.Stdout)
+		case "json":
+			err = json.NewEncoder(os.Stdout).Encode(capacities)
+		default:
+			log.Fatalf("Unsupported format %s", format)
+		}
+
+		if err != nil {
+			log.Fatalf("error writing capacity: %s", err)
+		}
+		return nil
+	},
+}
+
+var capacityHeader = []string{"developer", "week", "work days", "available", "allocated", "free"}
+
+func capacityRecord(capacity *planner.WeekCapacity) []string {
+	return []string{
+		string(capacity.DevId),
+		fmt.Sprintf("%d-W%02d", capacity.Year, capacity.Week),
+		strconv.Itoa(capacity.WorkDays),
+		strconv.FormatFloat(capacity.Available, 'f', 2, 64),
+		strconv.FormatFloat(capacity.Allocated, 'f', 2, 64),
+		strconv.FormatFloat(capacity.Free(), 'f', 2, 64),
+	}
+}
+
+func writeCapacityTable(capacities []*planner.WeekCapacity, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	writeRow := func(record []string) {
+		for _, field := range record {
+			_, _ = fmt.Fprintf(tw, "%s\t", field)
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+
+	writeRow(capacityHeader)
+	for _, capacity := range capacities {
+		writeRow(capacityRecord(capacity))
+	}
+	return tw.Flush()
+}
+
+func writeCapacityCSV(capacities []*planner.WeekCapacity, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(capacityHeader)
+	for _, capacity := range capacities {
+		_ = cw.Write(capacityRecord(capacity))
+	}
+	cw.Flush()
+	return cw.Error()
+}
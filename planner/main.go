@@ -1,14 +1,16 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"fmt"
 	"github.com/ostapneko/planner"
 	"github.com/ostapneko/planner/gantt"
 	"github.com/urfave/cli/v2"
-	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 func main() {
@@ -21,7 +23,6 @@ func main() {
 				Aliases:   []string{"o"},
 				Usage:     "output file with tasks completed",
 				TakesFile: true,
-				Required:  true,
 			},
 			&cli.StringFlag{
 				Name:      "gantt",
@@ -32,68 +33,120 @@ func main() {
 			&cli.StringFlag{
 				Name:    "format",
 				Aliases: []string{"f"},
-				Usage:   "json or yaml",
-				Value:   "yaml",
+				Usage:   "yaml, json or toml. Defaults to the format of the output file extension",
 			},
+			&cli.StringFlag{
+				Name:  "input-format",
+				Usage: "yaml, json or toml. Defaults to the format of the input file extension",
+			},
+			&cli.StringFlag{
+				Name:  "today",
+				Usage: "day relative dates, such as today or +2w, are resolved against. Defaults to the current day",
+			},
+			&cli.BoolFlag{
+				Name:    "in-place",
+				Aliases: []string{"i"},
+				Usage:   "update the computed fields in the input planning file itself, instead of an output file",
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "write nothing, and exit with an error when the output file is not up to date with the forecast",
+			},
+			&cli.StringSliceFlag{
+				Name:      "overlay",
+				Usage:     "planning file layered on top of the input planning, to forecast a scenario. Can be repeated",
+				TakesFile: true,
+			},
+		},
+		Commands: []*cli.Command{
+			capacityCommand,
+			optimizeCommand,
+			backwardCommand,
+			offDaysCommand,
+			schemaCommand,
+		},
+		Before: func(c *cli.Context) error {
+			inputFormat = c.String("input-format")
+			if c.IsSet("today") {
+				today, err := planner.DateToDay(c.String("today"))
+				if err != nil {
+					log.Fatalf("invalid today: %s", err)
+				}
+				planner.Clock = func() time.Time {
+					return today.Time()
+				}
+			}
+			return nil
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 1 {
 				log.Fatalf("Require the input planning as argument")
 			}
+			// several plannings, such as the ones sharing a roster, can be updated in place at once
+			inputFiles := c.Args().Slice()
 
-			inputFile := c.Args().Get(0)
-
-			dat, err := ioutil.ReadFile(inputFile)
-
-			if err != nil {
-				log.Fatalf("could not read file %s", inputFile)
+			// the out flag cannot be marked as required, as it would then be required by the commands too
+			inPlace := c.Bool("in-place")
+			if !c.IsSet("out") && !inPlace {
+				log.Fatalf("Require an output file, or the in-place flag")
 			}
-
-			var planningInput planner.PlanningInput
-
-			err = yaml.Unmarshal(dat, &planningInput)
-
-			if err != nil {
-				log.Fatalf("error parsing planning: %s", err)
+			if c.IsSet("out") && inPlace {
+				log.Fatalf("The output file and the in-place flag cannot be used together")
 			}
-
-			planning, err := planner.NewPlanning(planningInput)
-
-			if err != nil {
-				log.Fatalf("error transforming planning input into planning: %s", err)
+			if c.IsSet("overlay") && inPlace {
+				log.Fatalf("A scenario cannot be written in place, as it would replace the input planning")
 			}
-
-			err = planner.CheckPlanning(planning)
-
-			if err != nil {
-				log.Fatalf("inconsistent planning: %s", err)
+			if len(inputFiles) > 1 && (!inPlace || c.IsSet("gantt")) {
+				log.Fatalf("Several plannings can only be updated in place, without a Gantt chart")
 			}
 
-			planner.ForecastCompletion(planning)
+			inputFile := inputFiles[0]
+			planning := forecast(inputFile, c.StringSlice("overlay")...)
 
-			planningOutput := planner.NewPlanningInput(planning)
+			if c.IsSet("overlay") {
+				printScenarioDiff(forecast(inputFile), planning)
+			}
 
-			var doc []byte
+			plannings := map[string]*planner.Planning{inputFile: planning}
+			for _, file := range inputFiles[1:] {
+				plannings[file] = forecast(file)
+			}
 
-			format := c.String("format")
-			if format == "yaml" {
-				doc, _ = yaml.Marshal(planningOutput)
-			} else if format == "json" {
-				doc, _ = json.Marshal(planningOutput)
+			var outputs []*outputFile
+			if inPlace {
+				for _, file := range inputFiles {
+					format := c.String("format")
+					if format == "" {
+						format = sourceFormat(file)
+					}
+					outputs = append(outputs, planningOutputs(plannings[file], file, file, format, false)...)
+				}
 			} else {
-				log.Fatalf("Unsupported format %s", format)
+				outputs = planningOutputs(planning, inputFile, c.String("out"), c.String("format"), c.IsSet("overlay"))
 			}
 
-			outFile := c.String("out")
-			err = ioutil.WriteFile(outFile, doc, 0644)
+			if c.Bool("check") {
+				upToDate := true
+				for _, output := range outputs {
+					current, err := ioutil.ReadFile(output.path)
+					if err != nil || !bytes.Equal(current, output.doc) {
+						fmt.Printf("%s is not up to date, run planner to update it\n", output.path)
+						upToDate = false
+					}
+				}
+				if !upToDate {
+					os.Exit(1)
+				}
+				return nil
+			}
 
-			if err != nil {
-				log.Fatalf("error writing to file %s", outFile)
+			for _, output := range outputs {
+				writeFile(output.path, output.doc)
 			}
 
 			if c.IsSet("gantt") {
 				gantFile := c.String("gantt")
-				file, err := os.OpenFile(gantFile, os.O_WRONLY|os.O_CREATE, 0644)
+				file, err := os.OpenFile(gantFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 				if err != nil {
 					log.Fatalf("could not write Gantt chart to %s", gantFile)
 				}
@@ -109,3 +162,207 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// inputFormat is the format of the input planning, detected from its extension when empty
+var inputFormat string
+
+// forecast reads and checks the planning file, with the overlays layered on top of it, along with the other plannings
+// sharing its roster, and computes its completion dates
+func forecast(inputFile string, overlayFiles ...string) *planner.Planning {
+	planningInput, err := planner.ReadPlanningInputAs(inputFile, inputFormat)
+
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	for _, overlayFile := range overlayFiles {
+		overlay, err := planner.ReadOverlayInput(overlayFile)
+
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+
+		planner.ApplyOverlay(planningInput, overlay)
+	}
+
+	planning, err := planner.NewPlanning(*planningInput)
+
+	if err != nil {
+		log.Fatalf("error transforming planning input into planning: %s", err)
+	}
+
+	err = planner.CheckPlanning(planning)
+
+	if err != nil {
+		log.Fatalf("inconsistent planning: %s", err)
+	}
+
+	// the plannings sharing its roster are checked along with it, so that they do not book its developers twice
+	plannings, err := planner.ReadRosterPlannings(inputFile, planning)
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	plannings[inputFile] = planning
+	if err := planner.CheckSharedRoster(plannings); err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	planner.ForecastCompletion(planning)
+	planner.AnalyzeCriticalPath(planning)
+
+	return planning
+}
+
+// writePlanning writes the planning read from the input file to the output file, in the yaml, json or toml format,
+// or in the format of the output file extension if the format is empty, see planningOutputs
+func writePlanning(planning *planner.Planning, inputFile string, outFile string, format string) {
+	for _, output := range planningOutputs(planning, inputFile, outFile, format, false) {
+		writeFile(output.path, output.doc)
+	}
+}
+
+// outputFile is a file written by planner
+type outputFile struct {
+	path string
+	doc  []byte
+}
+
+// planningOutputs renders the planning read from the input file into the output file. A scenario is not the input
+// planning, so it is written in full rather than as an update of the input. The tasks of the included files are
+// rendered back into them, in their own format, only when the planning is updated in place: otherwise the included
+// files are left as they are, and the output is written in full, with their entries, so that it has the forecast of
+// all the tasks. It refers to the roster and iCalendar files from its own directory.
+func planningOutputs(planning *planner.Planning, inputFile string, outFile string, format string, scenario bool) []*outputFile {
+	inPlace := outFile == inputFile && !scenario
+	if !inPlace {
+		if err := planner.RebasePaths(planning, filepath.Dir(inputFile), filepath.Dir(outFile)); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	sourceFile := inputFile
+	if scenario {
+		sourceFile = ""
+	}
+	if !inPlace && len(planning.Includes) > 0 {
+		planning.Includes = nil
+		sourceFile = ""
+	}
+	outputs := []*outputFile{{path: outFile, doc: planningDoc(planning, outFile, format, sourceFile)}}
+	if !inPlace {
+		return outputs
+	}
+
+	for _, include := range planning.Includes {
+		if len(include.Tasks) == 0 {
+			continue
+		}
+
+		path := include.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(inputFile), path)
+		}
+
+		var doc []byte
+		var err error
+		if planner.FormatOf(path) == planner.YAML {
+			var source []byte
+			source, err = ioutil.ReadFile(path)
+			if err != nil {
+				log.Fatalf("could not read file %s", path)
+			}
+			doc, err = planner.UpdateIncludeYAML(source, planning, include)
+		} else {
+			doc, err = planner.Marshal(planner.FormatOf(path), planner.NewIncludeInput(planning, include))
+		}
+		if err != nil {
+			log.Fatalf("error updating included file %s: %s", path, err)
+		}
+		outputs = append(outputs, &outputFile{path: path, doc: doc})
+	}
+	return outputs
+}
+
+// planningDoc renders the planning in the yaml, json or toml format, or in the format of the output file extension
+// if the format is empty. When both the output and the source planning file are YAML, the computed fields are updated
+// in the source document, so that its comments and layout are kept.
+func planningDoc(planning *planner.Planning, outFile string, format string, sourceFile string) []byte {
+	if format == "" {
+		format = planner.FormatOf(outFile)
+	}
+
+	if sourceFile != "" && format == planner.YAML && sourceFormat(sourceFile) == planner.YAML {
+		source, err := ioutil.ReadFile(sourceFile)
+		if err != nil {
+			log.Fatalf("could not read file %s", sourceFile)
+		}
+		doc, err := planner.UpdateYAML(source, planning)
+		if err != nil {
+			log.Fatalf("error updating planning %s: %s", sourceFile, err)
+		}
+		return doc
+	}
+
+	doc, err := planner.Marshal(format, planner.NewPlanningInput(planning))
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	return doc
+}
+
+// writeFile replaces the file atomically: the content is written to a temporary file of the same directory,
+// which is then renamed, so that the file is never left half written, even when it is the input planning
+func writeFile(path string, doc []byte) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		log.Fatalf("error writing to file %s: %s", path, err)
+	}
+
+	_, err = tmp.Write(doc)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		log.Fatalf("error writing to file %s: %s", path, err)
+	}
+}
+
+// sourceFormat is the format the input planning file was read in
+func sourceFormat(inputFile string) string {
+	if inputFormat != "" {
+		return inputFormat
+	}
+	return planner.FormatOf(inputFile)
+}
+
+// printScenarioDiff reports the tasks whose completion date differs between the base planning and the scenario
+func printScenarioDiff(base *planner.Planning, scenario *planner.Planning) {
+	diffs := planner.DiffForecasts(base, scenario)
+	if len(diffs) == 0 {
+		fmt.Println("The scenario does not change the forecast")
+		return
+	}
+
+	for _, diff := range diffs {
+		fmt.Println(diff.Format(scenario.FormatDate))
+	}
+
+	baseLastDay := planner.LastPlannedDay(base)
+	scenarioLastDay := planner.LastPlannedDay(scenario)
+	if baseLastDay != nil && scenarioLastDay != nil {
+		fmt.Printf("Planning completed on %s instead of %s (%+d days)\n",
+			scenario.FormatDate(*scenarioLastDay), scenario.FormatDate(*baseLastDay), *scenarioLastDay-*baseLastDay)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ostapneko/planner"
+	"github.com/urfave/cli/v2"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+var offDaysCommand = &cli.Command{
+	Name:      "offdays",
+	Usage:     "count the off days of each developer by type",
+	ArgsUsage: "planning",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "table, csv or json",
+			Value:   "table",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			log.Fatalf("Require the input planning as argument")
+		}
+
+		planning := forecast(c.Args().Get(0))
+		counts := planner.CountOffDays(planning)
+
+		var err error
+		switch format := c.String("format"); format {
+		case "table":
+			err = writeOffDaysTable(counts, os.Stdout)
+		case "csv":
+			err = writeOffDaysCSV(counts, os.Stdout)
+		case "json":
+			err = json.NewEncoder(os.Stdout).Encode(counts)
+		default:
+			log.Fatalf("Unsupported format %s", format)
+		}
+
+		if err != nil {
+			log.Fatalf("error writing off days: %s", err)
+		}
+		return nil
+	},
+}
+
+var offDaysHeader = []string{"developer", "type", "days"}
+
+func offDaysRecord(count *planner.OffDayCount) []string {
+	offDayType := string(count.Type)
+	if offDayType == "" {
+		offDayType = "other"
+	}
+	return []string{
+		string(count.DevId),
+		offDayType,
+		strconv.Itoa(count.Days),
+	}
+}
+
+func writeOffDaysTable(counts []*planner.OffDayCount, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	writeRow := func(record []string) {
+		for _, field := range record {
+			_, _ = fmt.Fprintf(tw, "%s\t", field)
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+
+	writeRow(offDaysHeader)
+	for _, count := range counts {
+		writeRow(offDaysRecord(count))
+	}
+	return tw.Flush()
+}
+
+func writeOffDaysCSV(counts []*planner.OffDayCount, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(offDaysHeader)
+	for _, count := range counts {
+		_ = cw.Write(offDaysRecord(count))
+	}
+	cw.Flush()
+	return cw.Error()
+}
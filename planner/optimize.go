@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ostapneko/planner"
+	"github.com/urfave/cli/v2"
+	"log"
+)
+
+var optimizeCommand = &cli.Command{
+	Name:      "optimize",
+	Usage:     "suggest a priority order of the tasks that minimizes their lateness, given their deadlines and weights",
+	ArgsUsage: "planning",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:      "out",
+			Aliases:   []string{"o"},
+			Usage:     "output file with the tasks in the suggested order, and completed",
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "yaml, json or toml. Defaults to the format of the output file extension",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() < 1 {
+			log.Fatalf("Require the input planning as argument")
+		}
+
+		planning := forecast(c.Args().Get(0))
+		before := planner.WeightedLateness(planning)
+		unplannedBefore := planner.UnplannedWeight(planning)
+		after := planner.OptimizeOrder(planning)
+		planner.AnalyzeCriticalPath(planning)
+
+		fmt.Printf("Weighted lateness: %v days, instead of %v days\n", after, before)
+		if unplanned := planner.UnplannedWeight(planning); unplanned > 0 || unplannedBefore > 0 {
+			fmt.Printf("Weight of the unplanned tasks: %v, instead of %v\n", unplanned, unplannedBefore)
+		}
+		printOrder(planning, "Roadmap", planning.Tasks)
+		for _, project := range planning.Projects {
+			printOrder(planning, project.Name, project.Tasks)
+		}
+
+		if c.IsSet("out") {
+			writePlanning(planning, c.Args().Get(0), c.String("out"), c.String("format"))
+		}
+		return nil
+	},
+}
+
+func printOrder(planning *planner.Planning, title string, tasks []*planner.Task) {
+	if len(tasks) == 0 {
+		return
+	}
+
+	fmt.Printf("-- %s --\n", title)
+	for i, task := range tasks {
+		line := fmt.Sprintf("%d. %s", i+1, task.Name)
+		if task.Pinned {
+			line += " (pinned)"
+		}
+		if task.LastDay != nil {
+			line += fmt.Sprintf(", completed on %s", planning.FormatDate(*task.LastDay))
+		} else if len(task.Attributions) > 0 || len(task.Subtasks) > 0 {
+			line += ", not planned"
+		}
+		if task.Deadline != nil {
+			line += fmt.Sprintf(", deadline %s", planning.FormatDate(*task.Deadline))
+			if task.LastDay != nil && *task.LastDay > *task.Deadline {
+				line += fmt.Sprintf(" (%d days late)", *task.LastDay-*task.Deadline)
+			}
+		}
+		fmt.Println(line)
+	}
+}
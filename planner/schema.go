@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ostapneko/planner"
+	"github.com/urfave/cli/v2"
+	"log"
+)
+
+var schemaCommand = &cli.Command{
+	Name:      "schema",
+	Usage:     "print the JSON Schema of a kind of file, for editors to complete and validate them",
+	ArgsUsage: "[planning|roster|include|overlay], defaults to planning",
+	Action: func(c *cli.Context) error {
+		kind := planner.PlanningFile
+		if c.NArg() > 0 {
+			kind = c.Args().Get(0)
+		}
+		schema, err := planner.Schema(kind)
+		if err != nil {
+			log.Fatalf("error generating the schema: %s", err)
+		}
+		fmt.Println(string(schema))
+		return nil
+	},
+}
@@ -61,6 +61,8 @@ func Test_checkPlanning(t *testing.T) {
 		name    string
 		args    args
 		wantErr bool
+		// expected error, when the test checks it
+		err string
 	}{
 		{
 			name: "valid graph",
@@ -102,12 +104,39 @@ func Test_checkPlanning(t *testing.T) {
 			}},
 			wantErr: true,
 		},
+		{
+			name: "duplicate developer",
+			args: args{&Planning{
+				Tasks:      []*Task{task1},
+				Developers: []*Developer{dev1, dev1},
+				Holidays:   holidays,
+			}},
+			wantErr: true,
+			err:     "developer dev1 is defined more than once",
+		},
+		{
+			name: "duplicate task id",
+			args: args{&Planning{
+				Tasks: []*Task{
+					{Id: "T-1", Name: "Task", Attributions: attributions1},
+					{Id: "T-1", Name: "Other task", Subtasks: []*Task{{Id: "T-2", Name: "Subtask", Attributions: attributions1}}},
+				},
+				Developers: []*Developer{dev1},
+				Holidays:   holidays,
+			}},
+			wantErr: true,
+			err:     "task id T-1 is used by more than one task",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := CheckPlanning(tt.args.planning); (err != nil) != tt.wantErr {
+			err := CheckPlanning(tt.args.planning)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("CheckPlanning() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("CheckPlanning() error = %v, exp %s", err, tt.err)
+			}
 		})
 	}
 }
@@ -233,3 +262,298 @@ func TestForecastCompletion(t *testing.T) {
 		}
 	}
 }
+
+func TestForecastCompletionSubtasks(t *testing.T) {
+	subtask1 := &Task{
+		Name: "subtask1",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 2},
+		},
+	}
+	subtask2 := &Task{
+		Name: "subtask2",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 1},
+			"dev2": {EffortDays: 3},
+		},
+	}
+	parent := &Task{
+		Name: "parent",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev2": {EffortDays: 1},
+		},
+		Subtasks: []*Task{subtask1, subtask2},
+	}
+	task2 := &Task{
+		Name: "task2",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 1},
+		},
+	}
+	planning := &Planning{
+		StartDay: 4,
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 1},
+			{Id: "dev2", Utilization: 1},
+		},
+		Tasks: []*Task{parent, task2},
+	}
+
+	ForecastCompletion(planning)
+
+	// saturdays + sundays: 2, 3, 9, 10
+	// parent:
+	// dev2 (1d): 4
+	// subtask1:
+	// dev1 (2d): 4, 5
+	// subtask2:
+	// dev1 (1d): 6
+	// dev2 (3d): 5, 6, 7
+	// parent first day: 4, last day: 7
+	// task2:
+	// dev1 (1d): 7
+
+	examples := []struct {
+		act Day
+		exp Day
+	}{
+		{act: *parent.Attributions["dev2"].FirstDay, exp: 4},
+		{act: *parent.Attributions["dev2"].LastDay, exp: 4},
+		{act: *subtask1.FirstDay, exp: 4},
+		{act: *subtask1.LastDay, exp: 5},
+		{act: *subtask2.Attributions["dev1"].FirstDay, exp: 6},
+		{act: *subtask2.Attributions["dev2"].FirstDay, exp: 5},
+		{act: *subtask2.LastDay, exp: 7},
+		{act: *parent.FirstDay, exp: 4},
+		{act: *parent.LastDay, exp: 7},
+		{act: *task2.FirstDay, exp: 7},
+		{act: *task2.LastDay, exp: 7},
+	}
+
+	for i, example := range examples {
+		if example.act != example.exp {
+			t.Errorf("exp %d, got %d in example %d", example.exp, example.act, i+1)
+		}
+	}
+}
+
+func TestForecastCompletionProjects(t *testing.T) {
+	webTask := &Task{
+		Name: "web task",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 2},
+		},
+	}
+	mobileTask := &Task{
+		Name: "mobile task",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 1},
+			"dev2": {EffortDays: 2},
+		},
+	}
+	planning := &Planning{
+		StartDay: 4,
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 1, Allocations: map[string]float64{"web": 0.5, "mobile": 0.5}},
+			{Id: "dev2", Utilization: 1},
+		},
+		Projects: []*Project{
+			{Name: "web", Tasks: []*Task{webTask}},
+			{Name: "mobile", Tasks: []*Task{mobileTask}},
+		},
+	}
+
+	if err := CheckPlanning(planning); err != nil {
+		t.Fatal(err)
+	}
+
+	ForecastCompletion(planning)
+
+	// saturdays + sundays: 9, 10
+	// web task:
+	// dev1 (2d / 0.5 = 4d): 4, 5, 6, 7
+	// mobile task:
+	// dev1 (1d / 0.5 = 2d): 4, 5
+	// dev2 (2d): 4, 5
+
+	examples := []struct {
+		act Day
+		exp Day
+	}{
+		{act: *webTask.Attributions["dev1"].FirstDay, exp: 4},
+		{act: *webTask.Attributions["dev1"].LastDay, exp: 7},
+		{act: *mobileTask.Attributions["dev1"].FirstDay, exp: 4},
+		{act: *mobileTask.Attributions["dev1"].LastDay, exp: 5},
+		{act: *mobileTask.LastDay, exp: 5},
+	}
+
+	for i, example := range examples {
+		if example.act != example.exp {
+			t.Errorf("exp %d, got %d in example %d", example.exp, example.act, i+1)
+		}
+	}
+}
+
+func Test_checkAllocations(t *testing.T) {
+	task := func() *Task {
+		return &Task{
+			Name:         "task",
+			Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 1}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		allocations map[string]float64
+		tasks       []*Task
+		wantErr     bool
+	}{
+		{
+			name:        "shared developer with allocations",
+			allocations: map[string]float64{"web": 0.6, "mobile": 0.4},
+			wantErr:     false,
+		},
+		{
+			name:    "shared developer without allocations",
+			wantErr: true,
+		},
+		{
+			name:        "missing allocation",
+			allocations: map[string]float64{"web": 0.6},
+			wantErr:     true,
+		},
+		{
+			name:        "over-allocated developer",
+			allocations: map[string]float64{"web": 0.6, "mobile": 0.6},
+			wantErr:     true,
+		},
+		{
+			name:        "allocated developer outside of a project",
+			allocations: map[string]float64{"web": 0.6, "mobile": 0.4},
+			tasks:       []*Task{task()},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planning := &Planning{
+				Developers: []*Developer{{Id: "dev1", Utilization: 1, Allocations: tt.allocations}},
+				Tasks:      tt.tasks,
+				Projects: []*Project{
+					{Name: "web", Tasks: []*Task{task()}},
+					{Name: "mobile", Tasks: []*Task{task()}},
+				},
+			}
+			if err := CheckPlanning(planning); (err != nil) != tt.wantErr {
+				t.Errorf("CheckPlanning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestForecastCompletionRampUp(t *testing.T) {
+	var starts Day = 4
+	task := &Task{
+		Name: "task",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 3},
+			"dev2": {EffortDays: 3},
+		},
+	}
+	planning := &Planning{
+		StartDay: 4,
+		RampUp:   []RampUpStep{{Weeks: 1, Utilization: 0.25}},
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 1, Starts: &starts},
+			{Id: "dev2", Utilization: 0.5, Starts: &starts, RampUp: []RampUpStep{{Weeks: 1, Utilization: 0.5}}},
+		},
+		Tasks: []*Task{task},
+	}
+
+	ForecastCompletion(planning)
+
+	// saturdays + sundays: 9, 10
+	// dev1 (0.25d per day during the first week): 4, 5, 6, 7, 8 (1.25d), then 11, 12 (3.25d)
+	// dev2 (0.25d per day during the first week): 4, 5, 6, 7, 8 (1.25d), then 0.5d per day: 11, 12, 13, 14 (3.25d)
+	examples := []struct {
+		act Day
+		exp Day
+	}{
+		{act: *task.Attributions["dev1"].FirstDay, exp: 4},
+		{act: *task.Attributions["dev1"].LastDay, exp: 12},
+		{act: *task.Attributions["dev2"].LastDay, exp: 14},
+	}
+
+	for i, example := range examples {
+		if example.act != example.exp {
+			t.Errorf("exp %d, got %d in example %d", example.exp, example.act, i+1)
+		}
+	}
+}
+
+func TestForecastCompletionCalendars(t *testing.T) {
+	task := &Task{
+		Name: "task",
+		Attributions: map[DeveloperId]*Attribution{
+			"dev1": {EffortDays: 2},
+			"dev2": {EffortDays: 2},
+		},
+	}
+	planning := &Planning{
+		StartDay:  4,
+		Calendars: map[string]Days{"FR": {4}, "DE": {5}},
+		Developers: []*Developer{
+			{Id: "dev1", Utilization: 1, Calendars: []string{"FR"}},
+			{Id: "dev2", Utilization: 1, Calendars: []string{"FR", "DE"}},
+		},
+		Tasks: []*Task{task},
+	}
+
+	if err := CheckPlanning(planning); err != nil {
+		t.Fatal(err)
+	}
+
+	ForecastCompletion(planning)
+
+	// dev1 (2d): 5, 6
+	// dev2 (2d): 6, 7
+	if *task.Attributions["dev1"].LastDay != 6 || *task.Attributions["dev2"].LastDay != 7 {
+		t.Errorf("exp last days 6 and 7, got %d and %d", *task.Attributions["dev1"].LastDay, *task.Attributions["dev2"].LastDay)
+	}
+
+	planning.Developers[0].Calendars = []string{"US"}
+	if err := CheckPlanning(planning); err == nil {
+		t.Errorf("exp an error for an unknown calendar")
+	}
+}
+
+func TestForecastCompletionNoUtilization(t *testing.T) {
+	task := &Task{
+		Name:         "task",
+		Attributions: map[DeveloperId]*Attribution{"dev1": {EffortDays: 1}},
+	}
+	planning := &Planning{
+		StartDay:   4,
+		Developers: []*Developer{{Id: "dev1", Utilization: 0}},
+		Tasks:      []*Task{task},
+	}
+
+	// a developer who does not work leaves the task unplanned, rather than being scheduled forever
+	ForecastCompletion(planning)
+	if task.LastDay != nil {
+		t.Errorf("exp the task not to be planned, got %d", *task.LastDay)
+	}
+	if _, err := ScheduleBackward(planning, "task", 20); err == nil {
+		t.Errorf("exp an error for a developer who does not work")
+	}
+
+	for _, utilization := range []float64{0, -0.5, 1.5} {
+		input := PlanningInput{
+			StartDay:   "04/01/2021",
+			Developers: []*DeveloperInput{{Id: "dev1", Utilization: &utilization}},
+		}
+		if _, err := NewPlanning(input); err == nil {
+			t.Errorf("exp an error for a utilization of %v", utilization)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package planner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DaysInput is an entry of a list of days, such as holidays or off days. It is either:
+// - a single date, written as a plain date, or as a date field when the entry has a name or a type
+// - a range of dates, from and to included
+// - a recurrence between from and to: a weekday every given number of weeks, starting with the first one from the
+// from date, or the nth weekday of every month, the last one if nth is -1
+// Any entry can have a name, such as the name of a holiday, and off days a type, the reason why they are not worked.
+type DaysInput struct {
+	Date    string     `yaml:"date,omitempty" json:"date,omitempty" toml:"date,omitempty" description:"A single day."`
+	From    string     `yaml:"from,omitempty" json:"from,omitempty" toml:"from,omitempty" description:"The first day of a range or of a recurrence."`
+	To      string     `yaml:"to,omitempty" json:"to,omitempty" toml:"to,omitempty" description:"The last day of a range or of a recurrence, included."`
+	Weekday string     `yaml:"weekday,omitempty" json:"weekday,omitempty" toml:"weekday,omitempty" description:"The weekday of a recurrence."`
+	Every   int        `yaml:"every,omitempty" json:"every,omitempty" toml:"every,omitempty" description:"A recurrence on the weekday every given number of weeks, starting with the first one from the from day."`
+	Nth     int        `yaml:"nth,omitempty" json:"nth,omitempty" toml:"nth,omitempty" description:"A recurrence on the nth weekday of every month, the last one if -1."`
+	Name    string     `yaml:"name,omitempty" json:"name,omitempty" toml:"name,omitempty" description:"The name of the days, such as the name of a holiday, labelled in the Gantt chart."`
+	Type    OffDayType `yaml:"type,omitempty" json:"type,omitempty" toml:"type,omitempty" description:"The reason why off days are not worked."`
+}
+
+// OffDayType is the reason why a developer does not work on an off day
+type OffDayType string
+
+const (
+	Vacation   OffDayType = "vacation"
+	Sick       OffDayType = "sick"
+	Training   OffDayType = "training"
+	Conference OffDayType = "conference"
+	Parental   OffDayType = "parental"
+)
+
+var offDayTypes = map[OffDayType]bool{
+	Vacation:   true,
+	Sick:       true,
+	Training:   true,
+	Conference: true,
+	Parental:   true,
+}
+
+// OffDayReason labels an off day with the name and type of the entry it comes from
+type OffDayReason struct {
+	Name string
+	Type OffDayType
+}
+
+// daysInputFields has the fields of DaysInput, without its (un)marshaling methods
+type daysInputFields DaysInput
+
+func (input *DaysInput) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var date string
+	if err := unmarshal(&date); err == nil {
+		*input = DaysInput{Date: date}
+		return nil
+	}
+	return unmarshal((*daysInputFields)(input))
+}
+
+// labelled tells whether the entry has a name or a type, which a plain date cannot hold
+func (input *DaysInput) labelled() bool {
+	return input.Name != "" || input.Type != ""
+}
+
+func (input *DaysInput) MarshalYAML() (interface{}, error) {
+	if input.Date != "" && !input.labelled() {
+		return input.Date, nil
+	}
+	return (*daysInputFields)(input), nil
+}
+
+func (input *DaysInput) UnmarshalJSON(data []byte) error {
+	var date string
+	if err := json.Unmarshal(data, &date); err == nil {
+		*input = DaysInput{Date: date}
+		return nil
+	}
+	return json.Unmarshal(data, (*daysInputFields)(input))
+}
+
+func (input *DaysInput) MarshalJSON() ([]byte, error) {
+	if input.Date != "" && !input.labelled() {
+		return json.Marshal(input.Date)
+	}
+	return json.Marshal((*daysInputFields)(input))
+}
+
+func (input *DaysInput) UnmarshalTOML(data interface{}) error {
+	switch value := data.(type) {
+	case string:
+		*input = DaysInput{Date: value}
+		return nil
+	case map[string]interface{}:
+		// the fields have the same names in JSON
+		dat, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(dat, (*daysInputFields)(input))
+	default:
+		return fmt.Errorf("%v should be a quoted date or a table", data)
+	}
+}
+
+// MarshalTOML writes the entry as a quoted date, or as an inline table, as entries of an array cannot be tables
+// otherwise when some of them are dates
+func (input *DaysInput) MarshalTOML() ([]byte, error) {
+	if input.Date != "" && !input.labelled() {
+		return []byte(strconv.Quote(input.Date)), nil
+	}
+
+	fields := make([]string, 0)
+	addString := func(key string, value string) {
+		if value != "" {
+			fields = append(fields, fmt.Sprintf("%s = %s", key, strconv.Quote(value)))
+		}
+	}
+	addInt := func(key string, value int) {
+		if value != 0 {
+			fields = append(fields, fmt.Sprintf("%s = %d", key, value))
+		}
+	}
+	addString("date", input.Date)
+	addString("from", input.From)
+	addString("to", input.To)
+	addString("weekday", input.Weekday)
+	addInt("every", input.Every)
+	addInt("nth", input.Nth)
+	addString("name", input.Name)
+	addString("type", string(input.Type))
+	return []byte("{ " + strings.Join(fields, ", ") + " }"), nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// days expands the entry into the days it covers
+func (input *DaysInput) days(dates *dateParser) (Days, error) {
+	if input.Type != "" && !offDayTypes[input.Type] {
+		return nil, fmt.Errorf("unknown off day type %s", input.Type)
+	}
+
+	if input.Date != "" {
+		if input.From != "" || input.To != "" {
+			return nil, fmt.Errorf("entry %+v cannot have both a date and a range", *input)
+		}
+		day, err := dates.parse(input.Date)
+		if err != nil {
+			return nil, err
+		}
+		return Days{day}, nil
+	}
+
+	if input.From == "" || input.To == "" {
+		return nil, fmt.Errorf("range %+v needs a from and a to date", *input)
+	}
+	from, err := dates.parse(input.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := dates.parse(input.To)
+	if err != nil {
+		return nil, err
+	}
+	if to < from {
+		return nil, fmt.Errorf("range %+v ends before it starts", *input)
+	}
+
+	days := make(Days, 0)
+	if input.Weekday == "" {
+		if input.Every != 0 || input.Nth != 0 {
+			return nil, fmt.Errorf("recurrence %+v needs a weekday", *input)
+		}
+		for day := from; day <= to; day++ {
+			days = append(days, day)
+		}
+		return days, nil
+	}
+
+	weekday, prs := weekdays[strings.ToLower(input.Weekday)]
+	if !prs {
+		return nil, fmt.Errorf("unknown weekday %s", input.Weekday)
+	}
+
+	if input.Nth != 0 {
+		if input.Every != 0 {
+			return nil, fmt.Errorf("recurrence %+v cannot have both every and nth", *input)
+		}
+		if input.Nth < -1 || input.Nth > 5 {
+			return nil, fmt.Errorf("nth of recurrence %+v needs to be between 1 and 5, or -1", *input)
+		}
+		for year, month := from.Year(), from.Month(); NewDay(year, month, 1) <= to; month++ {
+			day := nthWeekday(input.Nth, weekday, month, "").day(year)
+			// the fifth weekday of a month spills over the next month when it does not exist
+			if day.Month() == NewDay(year, month, 1).Month() && day >= from && day <= to {
+				days = append(days, day)
+			}
+		}
+		return days, nil
+	}
+
+	every := input.Every
+	if every == 0 {
+		every = 1
+	}
+	if every < 0 {
+		return nil, fmt.Errorf("every of recurrence %+v needs to be positive", *input)
+	}
+	first := from + Day((int(weekday)-int(from.Weekday())+7)%7)
+	for day := first; day <= to; day += Day(7 * every) {
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// newDays expands the entries into the days they cover
+func newDays(inputs []*DaysInput, dates *dateParser) (Days, error) {
+	days := make(Days, 0, len(inputs))
+	for _, input := range inputs {
+		inputDays, err := input.days(dates)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, inputDays...)
+	}
+	return days, nil
+}
+
+// newDayNames maps the days of the named entries to their names
+func newDayNames(inputs []*DaysInput, names map[Day]string, dates *dateParser) error {
+	for _, input := range inputs {
+		if input.Name == "" {
+			continue
+		}
+		days, err := input.days(dates)
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			names[day] = input.Name
+		}
+	}
+	return nil
+}
+
+// newOffDayReasons maps the days of the labelled entries to their names and types
+func newOffDayReasons(inputs []*DaysInput, dates *dateParser) (map[Day]OffDayReason, error) {
+	reasons := make(map[Day]OffDayReason)
+	for _, input := range inputs {
+		if !input.labelled() {
+			continue
+		}
+		days, err := input.days(dates)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			reasons[day] = OffDayReason{Name: input.Name, Type: input.Type}
+		}
+	}
+	return reasons, nil
+}
+
+// newDaysInputs writes the days in a compact form, where contiguous days are ranges, except the excluded ones
+func newDaysInputs(days Days, excluded map[Day]bool, layout string) []*DaysInput {
+	inputs := make([]*DaysInput, 0)
+	for i := 0; i < len(days); i++ {
+		if excluded[days[i]] {
+			continue
+		}
+		last := i
+		for last+1 < len(days) && days[last+1] == days[last]+1 && !excluded[days[last+1]] {
+			last++
+		}
+		if last == i {
+			inputs = append(inputs, &DaysInput{Date: formatDate(layout, days[i])})
+		} else {
+			inputs = append(inputs, &DaysInput{From: formatDate(layout, days[i]), To: formatDate(layout, days[last])})
+		}
+		i = last
+	}
+	return inputs
+}
@@ -0,0 +1,76 @@
+package planner
+
+import (
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"testing"
+)
+
+func TestDaysInputDays(t *testing.T) {
+	doc := `
+- 02/01/1970
+- from: 05/01/1970
+  to: 07/01/1970
+- weekday: friday
+  every: 2
+  from: 05/01/1970
+  to: 31/01/1970
+- weekday: monday
+  nth: 1
+  from: 01/01/1970
+  to: 28/02/1970
+- weekday: saturday
+  nth: -1
+  from: 01/01/1970
+  to: 31/01/1970
+`
+	var inputs []*DaysInput
+	if err := yaml.Unmarshal([]byte(doc), &inputs); err != nil {
+		t.Fatal(err)
+	}
+
+	days, err := newDays(inputs, newDateParser(dateFormat))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 02/01/1970: 1
+	// 05/01/1970 to 07/01/1970: 4, 5, 6
+	// every other friday from 05/01/1970: 09/01 (8) and 23/01 (22)
+	// first monday of january and february: 05/01 (4) and 02/02 (32)
+	// last saturday of january: 31/01 (30)
+	exp := Days{1, 4, 5, 6, 8, 22, 4, 32, 30}
+	if !reflect.DeepEqual(days, exp) {
+		t.Errorf("exp %v, got %v", exp, days)
+	}
+
+	invalid := []*DaysInput{
+		{From: "05/01/1970"},
+		{From: "07/01/1970", To: "05/01/1970"},
+		{From: "05/01/1970", To: "07/01/1970", Weekday: "someday"},
+		{From: "05/01/1970", To: "07/01/1970", Every: 2},
+	}
+	for _, input := range invalid {
+		if _, err := input.days(newDateParser(dateFormat)); err == nil {
+			t.Errorf("exp an error for %+v", *input)
+		}
+	}
+}
+
+func TestNewDaysInputs(t *testing.T) {
+	inputs := newDaysInputs(Days{1, 4, 5, 6, 8, 9}, map[Day]bool{9: true}, dateFormat)
+
+	out, err := yaml.Marshal(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `- 02/01/1970
+- from: 05/01/1970
+  to: 07/01/1970
+- 09/01/1970
+`
+	if string(out) != exp {
+		t.Errorf("exp %s, got %s", exp, out)
+	}
+}
@@ -0,0 +1,340 @@
+package planner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// ReadPlanningInput reads a planning file, along with the roster file it refers to, if any.
+// The format of the files is detected from their extension.
+func ReadPlanningInput(path string) (*PlanningInput, error) {
+	return ReadPlanningInputAs(path, "")
+}
+
+// ReadPlanningInputAs reads a planning file in the given format, YAML, JSON or TOML, or in the format detected from
+// its extension if the format is empty. The roster file it refers to is read in the format of its own extension.
+// All the errors of the files are returned at once, inconsistencies of the planning included, with their position.
+func ReadPlanningInputAs(path string, format string) (*PlanningInput, error) {
+	return readInput(path, format, false)
+}
+
+// ReadOverlayInput reads an overlay file, in the format detected from its extension. Overlays use the date format,
+// start day and developers of the planning they are layered on, so their dates and developer ids are not checked.
+func ReadOverlayInput(path string) (*PlanningInput, error) {
+	return readInput(path, "", true)
+}
+
+// ReadRosterPlannings reads the other plannings the roster of the planning lists as sharing it, so that CheckSharedRoster
+// can check them along with the planning, read from the given path. The plannings are keyed by the path of their file.
+func ReadRosterPlannings(path string, planning *Planning) (map[string]*Planning, error) {
+	plannings := make(map[string]*Planning)
+	for _, siblingPath := range planning.RosterPlannings {
+		if sameFile(siblingPath, path) {
+			continue
+		}
+		input, err := ReadPlanningInput(siblingPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading planning %s sharing roster %s: %s", siblingPath, planning.Roster, err)
+		}
+		sibling, err := NewPlanning(*input)
+		if err != nil {
+			return nil, fmt.Errorf("error reading planning %s sharing roster %s: %s", siblingPath, planning.Roster, err)
+		}
+		plannings[siblingPath] = sibling
+	}
+	return plannings, nil
+}
+
+// sameFile tells whether two paths designate the same file
+func sameFile(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+func readInput(path string, format string, overlay bool) (*PlanningInput, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s", path)
+	}
+
+	if format == "" {
+		format = FormatOf(path)
+	}
+
+	// the whole planning is checked before it is decoded, so that all its errors are reported at once
+	var dates *dateParser
+	if !overlay {
+		dates = documentDates(dat, format)
+	}
+	checker := checkSource(path, format, dat, reflect.TypeOf(PlanningInput{}), dates)
+	errs := checker.errors
+	references := checker.references
+
+	var input PlanningInput
+	err = Unmarshal(format, dat, &input)
+	if err != nil && len(errs) == 0 {
+		return nil, fmt.Errorf("error parsing planning %s: %s", path, err)
+	}
+	input.positions = make(map[interface{}]*sourcePosition)
+	recordPositions(input.positions, path, format, dat, &input)
+	if input.StartDay == "" && !overlay {
+		errs = append(errs, &SourceError{Path: path, Message: "startDay is required"})
+	}
+
+	err = readICalendars(input.ICalendars, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	// the roster is read first, so that the included files are checked against it
+	if input.Roster != "" {
+		rosterReferences, err := readRoster(&input, path, dates)
+		if rosterErrs, ok := err.(SourceErrors); ok {
+			errs = append(errs, rosterErrs...)
+		} else if err != nil {
+			return nil, err
+		}
+		references = append(references, rosterReferences...)
+	}
+
+	if len(input.Include) > 0 {
+		includeReferences, err := readIncludes(&input, path, dates)
+		if includeErrs, ok := err.(SourceErrors); ok {
+			errs = append(errs, includeErrs...)
+		} else if err != nil {
+			return nil, err
+		}
+		references = append(references, includeReferences...)
+	}
+
+	if !overlay {
+		if err := checkReferences(references, input.Developers); err != nil {
+			errs = append(errs, err.(SourceErrors)...)
+		}
+
+		// the planning is also built and checked, so that its inconsistencies are reported along with the errors
+		// above, at the position of the items they are about, its developer references excepted, as they were just
+		// checked. When it cannot be built, it is most often because of the errors above, which are then reported alone.
+		planning, err := NewPlanning(input)
+		if err == nil {
+			errs = append(errs, asSourceErrors(path, checkPlanning(planning, false))...)
+		} else if len(errs) == 0 {
+			errs = append(errs, asSourceErrors(path, err)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		errs.sort()
+		return nil, errs
+	}
+	return &input, nil
+}
+
+// RebasePaths changes the relative paths of the files the planning refers to, its roster, included files and
+// iCalendar files, from the directory of the planning file to another directory, for the planning to be written there.
+// The iCalendar files of a roster are relative to the roster, and are left as they are.
+func RebasePaths(planning *Planning, fromDir string, toDir string) error {
+	from, err := filepath.Abs(fromDir)
+	if err != nil {
+		return err
+	}
+	to, err := filepath.Abs(toDir)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	rebase := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		rebased, err := filepath.Rel(to, filepath.Join(from, path))
+		if err != nil {
+			return filepath.Join(from, path)
+		}
+		return filepath.ToSlash(rebased)
+	}
+
+	if planning.Roster != "" {
+		planning.Roster = rebase(planning.Roster)
+	} else {
+		for _, ical := range planning.ICalendars {
+			ical.Path = rebase(ical.Path)
+		}
+	}
+	for _, include := range planning.Includes {
+		include.Path = rebase(include.Path)
+	}
+	return nil
+}
+
+// readICalendars reads the events of the iCalendar files, whose paths are relative to dir
+func readICalendars(icals []*ICalendarInput, dir string) error {
+	for _, ical := range icals {
+		path := ical.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("could not read iCalendar file %s", path)
+		}
+
+		events, err := ParseICalendar(file)
+		_ = file.Close()
+		if err != nil {
+			return fmt.Errorf("error parsing iCalendar %s: %s", path, err)
+		}
+		ical.Events = events
+	}
+	return nil
+}
+
+// readIncludes adds the holidays, developers, support weeks and tasks of the included files to the planning input,
+// after its own, in the order of the files. A top-level task without an id defined in more than one file, or a holiday
+// date listed in more than one file, is an error, as developers and task ids defined more than once are for
+// CheckPlanning. When the planning has a roster, the included files can only have tasks. The included files are
+// checked with the dates of the planning, and the developer ids they refer to are returned.
+func readIncludes(input *PlanningInput, planningPath string, dates *dateParser) ([]*developerReference, error) {
+	errs := make(SourceErrors, 0)
+	references := make([]*developerReference, 0)
+
+	// the files each task and holiday date is defined in
+	taskFiles := make(map[string]string)
+	holidayFiles := make(map[string]string)
+	addDefinitions := func(path string, include *IncludeInput) {
+		for _, task := range include.Tasks {
+			// tasks with an id are identified by it rather than by their name, and CheckPlanning reports the ids
+			// used more than once, in any of the files
+			if task.Id != "" {
+				continue
+			}
+			if other, prs := taskFiles[task.Name]; prs && other != path {
+				errs = append(errs, &SourceError{Path: path, Message: fmt.Sprintf("task %s is already defined in %s", task.Name, other)})
+			}
+			taskFiles[task.Name] = path
+		}
+		for _, holiday := range include.Holidays {
+			if holiday.Date == "" {
+				continue
+			}
+			if other, prs := holidayFiles[holiday.Date]; prs && other != path {
+				errs = append(errs, &SourceError{Path: path, Message: fmt.Sprintf("holiday %s is already listed in %s", holiday.Date, other)})
+			}
+			holidayFiles[holiday.Date] = path
+		}
+	}
+	addDefinitions(planningPath, &IncludeInput{
+		Holidays: input.Holidays,
+		Tasks:    input.Tasks,
+	})
+
+	for _, includePath := range input.Include {
+		path := includePath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(planningPath), path)
+		}
+
+		dat, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read included file %s", path)
+		}
+
+		checker := checkSource(path, FormatOf(path), dat, reflect.TypeOf(IncludeInput{}), dates)
+		errs = append(errs, checker.errors...)
+		references = append(references, checker.references...)
+
+		var include IncludeInput
+		err = Unmarshal(FormatOf(path), dat, &include)
+		if err != nil && len(checker.errors) == 0 {
+			return nil, fmt.Errorf("error parsing included file %s: %s", path, err)
+		}
+		recordPositions(input.positions, path, FormatOf(path), dat, &include)
+
+		// the roster has all the developers, holidays and support weeks of the planning
+		if input.Roster != "" && (len(include.Developers) > 0 || len(include.Holidays) > 0 || len(include.SupportWeeks) > 0) {
+			errs = append(errs, &SourceError{
+				Path:    path,
+				Message: fmt.Sprintf("developers, holidays and support weeks need to be defined in the roster %s, not in included file %s", input.Roster, includePath),
+			})
+			include.Developers, include.Holidays, include.SupportWeeks = nil, nil, nil
+		}
+		addDefinitions(path, &include)
+
+		input.Holidays = append(input.Holidays, include.Holidays...)
+		input.Developers = append(input.Developers, include.Developers...)
+		input.SupportWeeks = append(input.SupportWeeks, include.SupportWeeks...)
+		input.Tasks = append(input.Tasks, include.Tasks...)
+		input.Included = append(input.Included, &include)
+	}
+
+	if len(errs) > 0 {
+		return references, errs
+	}
+	return references, nil
+}
+
+// readRoster fills the developers, holidays, calendars and support weeks of the planning input from its roster file.
+// These fields cannot be set in both the planning and the roster. The roster is checked with the dates of the planning,
+// and the developer ids it refers to are returned.
+func readRoster(input *PlanningInput, planningPath string, dates *dateParser) ([]*developerReference, error) {
+	path := input.Roster
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(planningPath), path)
+	}
+
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read roster file %s", path)
+	}
+
+	checker := checkSource(path, FormatOf(path), dat, reflect.TypeOf(RosterInput{}), dates)
+	errs := checker.errors
+
+	var roster RosterInput
+	err = Unmarshal(FormatOf(path), dat, &roster)
+	if err != nil && len(errs) == 0 {
+		return nil, fmt.Errorf("error parsing roster %s: %s", path, err)
+	}
+	recordPositions(input.positions, path, FormatOf(path), dat, &roster)
+
+	if len(input.Developers) > 0 || len(input.Holidays) > 0 || len(input.HolidayRules) > 0 || len(input.Calendars) > 0 ||
+		len(input.ICalendars) > 0 || len(input.SupportWeeks) > 0 {
+		errs = append(errs, &SourceError{
+			Path:    planningPath,
+			Message: fmt.Sprintf("developers, holidays, calendars and support weeks need to be defined in the roster %s, not in the planning", input.Roster),
+		})
+	}
+
+	err = readICalendars(roster.ICalendars, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	input.Developers = roster.Developers
+	input.Holidays = roster.Holidays
+	input.HolidayRules = roster.HolidayRules
+	input.Calendars = roster.Calendars
+	input.ICalendars = roster.ICalendars
+	input.SupportWeeks = roster.SupportWeeks
+	for _, planning := range roster.Plannings {
+		if !filepath.IsAbs(planning) {
+			planning = filepath.Join(filepath.Dir(path), planning)
+		}
+		input.RosterPlannings = append(input.RosterPlannings, planning)
+	}
+	if len(errs) > 0 {
+		return checker.references, errs
+	}
+	return checker.references, nil
+}
@@ -0,0 +1,308 @@
+package planner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadPlanningInputIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"planning.yaml": `
+startDay: 04/01/2021
+include: [team.yaml, backlog.json]
+tasks:
+  - name: task1
+    attributions:
+      dev1:
+        effort: 1
+`,
+		"team.yaml": `
+holidays: [05/01/2021]
+developers:
+  - id: dev1
+  - id: dev2
+`,
+		"backlog.json": `{"tasks": [{"name": "task2", "attributions": {"dev2": {"effort": 2}}}]}`,
+	})
+	defer os.RemoveAll(dir)
+
+	input, err := ReadPlanningInput(filepath.Join(dir, "planning.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(input.Developers) != 2 || len(input.Holidays) != 1 || len(input.Tasks) != 2 || input.Tasks[1].Name != "task2" {
+		t.Fatalf("exp the included entries to be added, got %+v", input)
+	}
+
+	planning, err := NewPlanning(*input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planning.Includes) != 2 || len(planning.Includes[0].Developers) != 2 || len(planning.Includes[1].Tasks) != 1 {
+		t.Fatalf("exp the entries of each included file, got %+v", planning.Includes)
+	}
+
+	// the entries of the included files are written back to them only
+	output := NewPlanningInput(planning)
+	if len(output.Developers) != 0 || len(output.Holidays) != 0 || len(output.Tasks) != 1 || len(output.Include) != 2 {
+		t.Errorf("exp the planning output to refer to the included files, got %+v", output)
+	}
+	backlog := NewIncludeInput(planning, planning.Includes[1])
+	if len(backlog.Tasks) != 1 || backlog.Tasks[0].Name != "task2" || len(backlog.Developers) != 0 {
+		t.Errorf("exp the included tasks, got %+v", backlog)
+	}
+}
+
+func TestReadPlanningInputIncludeDuplicates(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"planning.yaml": `
+startDay: 04/01/2021
+include: [team.yaml]
+holidays: [05/01/2021]
+developers:
+  - id: dev1
+`,
+		"team.yaml": `
+holidays: [05/01/2021]
+developers:
+  - id: dev1
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := ReadPlanningInput(filepath.Join(dir, "planning.yaml"))
+	errs, ok := err.(SourceErrors)
+	if !ok || len(errs) != 2 {
+		t.Errorf("exp the duplicate developer and holiday, got %v", err)
+	}
+
+	// tasks with an id are identified by it, rather than by their name
+	dir = writeFiles(t, map[string]string{
+		"planning.yaml": `
+startDay: 04/01/2021
+include: [backlog.yaml]
+developers:
+  - id: dev1
+tasks:
+  - id: T-1
+    name: task
+    attributions:
+      dev1: {effort: 1}
+`,
+		"backlog.yaml": `
+tasks:
+  - id: T-2
+    name: task
+    attributions:
+      dev1: {effort: 1}
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	if _, err := ReadPlanningInput(filepath.Join(dir, "planning.yaml")); err != nil {
+		t.Errorf("exp tasks with different ids to be different tasks, got %v", err)
+	}
+}
+
+func TestReadPlanningInputIncludeRoster(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"planning.yaml": `
+startDay: 04/01/2021
+roster: team.yaml
+include: [backlog.yaml, more.yaml]
+`,
+		"team.yaml": `
+developers:
+  - id: dev1
+`,
+		"backlog.yaml": `
+tasks:
+  - name: task1
+    attributions:
+      dev1: {effort: 1}
+`,
+		"more.yaml": `
+developers:
+  - id: dev2
+tasks:
+  - name: task2
+    attributions:
+      dev1: {effort: 1}
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	// the developers of the planning come from its roster only, the included file is blamed for its own
+	_, err := ReadPlanningInput(filepath.Join(dir, "planning.yaml"))
+	exp := filepath.Join(dir, "more.yaml") +
+		": developers, holidays and support weeks need to be defined in the roster team.yaml, not in included file more.yaml"
+	if err == nil || err.Error() != exp {
+		t.Errorf("exp the developers of the included file to be reported, got %v", err)
+	}
+}
+
+func TestReadPlanningInputDoc(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("doc", "*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" && ext != ".json" && ext != ".toml" {
+			continue
+		}
+		t.Run(path, func(t *testing.T) {
+			if _, err := ReadPlanningInput(path); err != nil {
+				t.Errorf("exp the example to be valid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadPlanningInputPlanningErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"planning.yaml": `
+startDay: 04/01/2021
+developers:
+  - id: dev1
+  - id: dev2
+supportWeeks:
+  - devId: dev1
+    firstDay: 04/01/2021
+    lastDay: 08/01/2021
+  - devId: dev2
+    firstDay: 08/01/2021
+    lastDay: 12/01/2021
+tasks:
+  - name: task1
+    attributions:
+      dev1: {effort: 1}
+  - name: task2
+`,
+		"nostart.yaml": `
+developers:
+  - id: dev1
+tasks:
+  - name: task1
+    deadline: 32/01/2021
+    attributions:
+      dev2: {effort: 1}
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "planning.yaml")
+	_, err := ReadPlanningInput(path)
+	exp := path + ":10:5: day 08/01/2021 is in the support weeks of both dev1 and dev2\n" +
+		path + ":17:5: task task2 needs to have at least one attribution or subtask"
+	if err == nil || err.Error() != exp {
+		t.Errorf("exp the inconsistencies at the position of their items, got %v", err)
+	}
+
+	// the dates and developers are checked even without a start day
+	path = filepath.Join(dir, "nostart.yaml")
+	_, err = ReadPlanningInput(path)
+	errs, ok := err.(SourceErrors)
+	if !ok || len(errs) != 3 {
+		t.Errorf("exp the missing start day, the invalid deadline and the unknown developer, got %v", err)
+	}
+}
+
+func TestCheckSharedRoster(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"team.yaml": `
+developers:
+  - id: Alice
+  - id: Bob
+    allocations:
+      web: 0.6
+      mobile: 0.4
+plannings: [web.yaml, mobile.yaml]
+`,
+		"web.yaml": `
+startDay: 04/01/2021
+roster: team.yaml
+projects:
+  - name: web
+    tasks:
+      - name: task1
+        attributions:
+          Alice: {effort: 2}
+          Bob: {effort: 2}
+`,
+		"mobile.yaml": `
+startDay: 04/01/2021
+roster: team.yaml
+projects:
+  - name: mobile
+    tasks:
+      - name: task2
+        attributions:
+          Alice: {effort: 1}
+          Bob: {effort: 1}
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "web.yaml")
+	input, err := ReadPlanningInput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planning, err := NewPlanning(*input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the other plannings listed by the roster are read along with the planning
+	plannings, err := ReadRosterPlannings(path, planning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, prs := plannings[filepath.Join(dir, "mobile.yaml")]; len(plannings) != 1 || !prs {
+		t.Fatalf("exp the mobile planning, got %v", plannings)
+	}
+	plannings[path] = planning
+
+	// Bob is split between the plannings, while both count on the whole capacity of Alice
+	err = CheckSharedRoster(plannings)
+	exp := filepath.Join(dir, "team.yaml") + ": developer Alice is booked for 2 of their capacity by plannings " +
+		filepath.Join(dir, "mobile.yaml") + ", " + filepath.Join(dir, "web.yaml")
+	if err == nil || err.Error() != exp {
+		t.Errorf("exp Alice to be double-booked, got %v", err)
+	}
+
+	delete(plannings, filepath.Join(dir, "mobile.yaml"))
+	if err := CheckSharedRoster(plannings); err != nil {
+		t.Errorf("exp a planning alone to book its roster, got %v", err)
+	}
+}
+
+func TestRebasePaths(t *testing.T) {
+	planning := &Planning{
+		Roster:   "team.yaml",
+		Includes: []*Include{{Path: "backlog/tasks.yaml"}, {Path: "/plans/holidays.yaml"}},
+	}
+	if err := RebasePaths(planning, "plans", "out/q1"); err != nil {
+		t.Fatal(err)
+	}
+	if planning.Roster != "../../plans/team.yaml" || planning.Includes[0].Path != "../../plans/backlog/tasks.yaml" ||
+		planning.Includes[1].Path != "/plans/holidays.yaml" {
+		t.Errorf("exp the relative paths to be rebased on the output directory, got %s, %s and %s",
+			planning.Roster, planning.Includes[0].Path, planning.Includes[1].Path)
+	}
+}
@@ -0,0 +1,201 @@
+package planner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock gives the current time, against which relative dates such as today are resolved.
+// It can be replaced, to forecast as of another day or to keep tests deterministic.
+var Clock = time.Now
+
+// Today returns the current day of the clock
+func Today() Day {
+	return DayOf(Clock())
+}
+
+// dateParser parses the dates of a planning, written with its layout, in the ISO 8601 format, or as relative dates:
+// - a base: today, startDay, an ISO week date such as 2021-W07-1, or the start or the end of the current week,
+// month, quarter or year, such as end-of-quarter
+// - followed by offsets in days (d), working days (wd, weekends excluded), weeks (w), months (m) or years (y), such as +3w or -2wd
+// A date made of offsets only is relative to today, and the base of a date with offsets can also be a plain date.
+type dateParser struct {
+	layout string
+	today  Day
+	// nil while the start day itself is parsed
+	startDay *Day
+}
+
+func newDateParser(layout string) *dateParser {
+	return &dateParser{layout: layout, today: Today()}
+}
+
+var dateOffsetRegexp = regexp.MustCompile(`([+-])\s*(\d+)(wd|d|w|m|y)$`)
+
+var isoWeekDateRegexp = regexp.MustCompile(`^(\d{4})-W(\d{2})-(\d)$`)
+
+var periodRegexp = regexp.MustCompile(`^(start|end)-of-(week|month|quarter|year)$`)
+
+type dateOffset struct {
+	sign  int
+	count int
+	unit  string
+}
+
+func (parser *dateParser) parse(str string) (Day, error) {
+	if strings.TrimSpace(str) == "" {
+		return 0, fmt.Errorf("the date is empty")
+	}
+	if day, err := parseDate(parser.layout, str); err == nil {
+		return day, nil
+	}
+
+	// offsets are read from the end, until only the base is left
+	base := strings.TrimSpace(str)
+	offsets := make([]dateOffset, 0)
+	for {
+		match := dateOffsetRegexp.FindStringSubmatch(base)
+		if match == nil {
+			break
+		}
+		count, _ := strconv.Atoi(match[2])
+		sign := 1
+		if match[1] == "-" {
+			sign = -1
+		}
+		offsets = append([]dateOffset{{sign: sign, count: count, unit: match[3]}}, offsets...)
+		base = strings.TrimSpace(strings.TrimSuffix(base, match[0]))
+	}
+
+	// offsets alone are relative to today
+	if base == "" {
+		base = "today"
+	}
+	day, err := parser.parseBase(base)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, offset := range offsets {
+		day = offset.apply(day)
+	}
+	return day, nil
+}
+
+// parseField parses the date of a required field, which cannot be empty
+func (parser *dateParser) parseField(field string, str string) (Day, error) {
+	if strings.TrimSpace(str) == "" {
+		return 0, fmt.Errorf("%s is required", field)
+	}
+	day, err := parser.parse(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", field, err)
+	}
+	return day, nil
+}
+
+func (parser *dateParser) parseBase(base string) (Day, error) {
+	switch base {
+	case "today":
+		return parser.today, nil
+	case "startDay":
+		if parser.startDay == nil {
+			return 0, fmt.Errorf("error parsing date %s, the start day cannot be relative to itself", base)
+		}
+		return *parser.startDay, nil
+	}
+
+	if match := isoWeekDateRegexp.FindStringSubmatch(base); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		week, _ := strconv.Atoi(match[2])
+		weekday, _ := strconv.Atoi(match[3])
+		if week < 1 || week > isoWeeksIn(year) || weekday < 1 || weekday > 7 {
+			return 0, fmt.Errorf("error parsing date %s, not a valid ISO week date", base)
+		}
+		// the first ISO week of a year is the one with January 4th
+		jan4 := NewDay(year, time.January, 4)
+		monday := jan4 - Day((int(jan4.Weekday())+6)%7)
+		return monday + Day((week-1)*7+weekday-1), nil
+	}
+
+	if match := periodRegexp.FindStringSubmatch(base); match != nil {
+		return periodBound(parser.today, match[2], match[1] == "end"), nil
+	}
+
+	return parseDate(parser.layout, base)
+}
+
+// isoWeeksIn returns the number of ISO weeks of the year, 52 or 53. December 28th is always in the last one.
+func isoWeeksIn(year int) int {
+	_, week := NewDay(year, time.December, 28).ISOWeek()
+	return week
+}
+
+// periodBound returns the first or last day of the week, month, quarter or year of the day
+func periodBound(day Day, period string, end bool) Day {
+	year, month, _ := day.Date()
+	var first Day
+	var next Day
+	switch period {
+	case "week":
+		first = day - Day((int(day.Weekday())+6)%7)
+		next = first + 7
+	case "month":
+		first = NewDay(year, month, 1)
+		next = first.AddDate(0, 1, 0)
+	case "quarter":
+		first = NewDay(year, (month-1)/3*3+1, 1)
+		next = first.AddDate(0, 3, 0)
+	default:
+		first = NewDay(year, time.January, 1)
+		next = first.AddDate(1, 0, 0)
+	}
+
+	if end {
+		return next - 1
+	}
+	return first
+}
+
+func (offset dateOffset) apply(day Day) Day {
+	switch offset.unit {
+	case "d":
+		return day + Day(offset.sign*offset.count)
+	case "w":
+		return day + Day(offset.sign*offset.count*7)
+	case "m":
+		return day.AddDate(0, offset.sign*offset.count, 0)
+	case "y":
+		return day.AddDate(offset.sign*offset.count, 0, 0)
+	default:
+		// working days skip weekends, but not holidays: they are not the same for every developer, and are not known
+		// yet while the dates are parsed
+		for i := 0; i < offset.count; i++ {
+			day += Day(offset.sign)
+			for isWeekEnd(day) {
+				day += Day(offset.sign)
+			}
+		}
+		return day
+	}
+}
+
+// expression returns the date when it is a relative date, so that it can be written back as it is rather than as the
+// day it resolves to, or an empty string otherwise
+func (parser *dateParser) expression(str string) string {
+	if _, err := parseDate(parser.layout, str); err == nil {
+		return ""
+	}
+	return str
+}
+
+// writeDate writes the day with the layout, or the relative date it was resolved from, if any
+func writeDate(layout string, day Day, expression string) string {
+	if expression != "" {
+		return expression
+	}
+	return formatDate(layout, day)
+}
@@ -0,0 +1,82 @@
+package planner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeDates(t *testing.T) {
+	clock := Clock
+	defer func() { Clock = clock }()
+	// a Wednesday
+	Clock = func() time.Time { return time.Date(2021, time.February, 3, 10, 0, 0, 0, time.UTC) }
+
+	today := NewDay(2021, time.February, 3)
+	startDay := NewDay(2021, time.February, 1)
+	dates := newDateParser(dateFormat)
+	dates.startDay = &startDay
+
+	cases := map[string]Day{
+		"today":            today,
+		"+3w":              today + 21,
+		"-1d":              today - 1,
+		"startDay+10wd":    startDay + 14,
+		"today+3wd":        today + 5,
+		"today-3wd":        today - 5,
+		"+1m":              NewDay(2021, time.March, 3),
+		"2021-W07-1":       NewDay(2021, time.February, 15),
+		"2021-W01-1":       NewDay(2021, time.January, 4),
+		"2020-W53-7":       NewDay(2021, time.January, 3),
+		"2026-W53-1":       NewDay(2026, time.December, 28),
+		"2021-W52-7":       NewDay(2022, time.January, 2),
+		"24/12/2021+1wd":   NewDay(2021, time.December, 27),
+		"end-of-quarter":   NewDay(2021, time.March, 31),
+		"start-of-week":    NewDay(2021, time.February, 1),
+		"end-of-month":     NewDay(2021, time.February, 28),
+		"start-of-year+1y": NewDay(2022, time.January, 1),
+		"01/03/2021+2d":    NewDay(2021, time.March, 3),
+		"2021-03-01 + 1w":  NewDay(2021, time.March, 8),
+		"10/02/2021":       NewDay(2021, time.February, 10),
+	}
+	for str, exp := range cases {
+		day, err := dates.parse(str)
+		if err != nil {
+			t.Errorf("%s: %s", str, err)
+			continue
+		}
+		if day != exp {
+			t.Errorf("%s: exp %s, got %s", str, DayToDate(exp), DayToDate(day))
+		}
+	}
+
+	// 2021 has 52 ISO weeks, and working days only skip weekends
+	for _, str := range []string{"", " ", "tomorrow", "+3x", "2021-W54-1", "2021-W53-1", "2021-W00-1", "end-of-decade"} {
+		if _, err := dates.parse(str); err == nil {
+			t.Errorf("exp an error for %s", str)
+		}
+	}
+
+	// the start day cannot refer to itself, and relative dates are written back as they are
+	if _, err := NewPlanning(PlanningInput{StartDay: "startDay+1d"}); err == nil {
+		t.Errorf("exp an error for a start day relative to itself")
+	}
+	// required dates are not today when they are missing
+	if _, err := NewPlanning(PlanningInput{}); err == nil || err.Error() != "error parsing start day: startDay is required" {
+		t.Errorf("exp an error for a missing start day, got %v", err)
+	}
+	week := &SupportWeekInput{FirstDay: "01/02/2021", DevId: "dev1"}
+	if _, err := NewPlanning(PlanningInput{StartDay: "today", SupportWeeks: []*SupportWeekInput{week}}); err == nil {
+		t.Errorf("exp an error for a support week without a last day")
+	}
+
+	planning, err := NewPlanning(PlanningInput{StartDay: "today"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if planning.StartDay != today {
+		t.Errorf("exp the start day to be today, got %s", DayToDate(planning.StartDay))
+	}
+	if output := NewPlanningInput(planning); output.StartDay != "today" {
+		t.Errorf("exp the start day written as today, got %s", output.StartDay)
+	}
+}
@@ -0,0 +1,187 @@
+package planner
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// datePattern matches the dates a planning accepts: dates in the format of the planning or in the ISO 8601 format,
+// and relative dates, made of a day and offsets, or of offsets only. The format of the planning can be set, so plain
+// dates are only matched loosely.
+const datePattern = `^((` + datePatternDay + `)(` + datePatternOffset + `)*|(` + datePatternOffset + `)+)$`
+
+const datePatternDay = `\d+[^A-Za-z\d]*\d+[^A-Za-z\d]*\d+|today|startDay|\d{4}-W\d{2}-\d|(start|end)-of-(week|month|quarter|year)`
+
+const datePatternOffset = `\s*[+-]\s*\d+(wd|d|w|m|y)`
+
+// the kinds of files planner reads, each with its own JSON Schema
+const (
+	PlanningFile = "planning"
+	RosterFile   = "roster"
+	IncludeFile  = "include"
+	OverlayFile  = "overlay"
+)
+
+// schemaFileTypes are the input types of the kinds of files
+var schemaFileTypes = map[string]reflect.Type{
+	PlanningFile: reflect.TypeOf(PlanningInput{}),
+	RosterFile:   reflect.TypeOf(RosterInput{}),
+	IncludeFile:  reflect.TypeOf(IncludeInput{}),
+	OverlayFile:  reflect.TypeOf(PlanningInput{}),
+}
+
+// schemaGenerator generates the JSON Schema of an input type from its fields, with a definition for each struct type
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// Schema returns the JSON Schema of a kind of file, a planning, roster, included or overlay file, generated from its
+// input type and their nested types. The fields are described by the description tag of the input types, and the
+// schema tag flags the required ones and the ones computed by planner. Editors can use it to complete and validate
+// the files.
+func Schema(kind string) ([]byte, error) {
+	t, prs := schemaFileTypes[kind]
+	if !prs {
+		return nil, fmt.Errorf("unknown kind of file %s, known ones are %s, %s, %s and %s",
+			kind, PlanningFile, RosterFile, IncludeFile, OverlayFile)
+	}
+
+	generator := &schemaGenerator{definitions: make(map[string]interface{})}
+	root := generator.structSchema(t)
+	// overlays only describe the differences with the planning they are layered on, and require nothing of their own
+	if kind == OverlayFile {
+		delete(root, "required")
+	}
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = strings.ToUpper(kind[:1]) + kind[1:]
+	root["definitions"] = generator.definitions
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func (generator *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	t = sourceType(indirect(t))
+	switch t {
+	case reflect.TypeOf(OffDayType("")):
+		types := make([]string, 0, len(offDayTypes))
+		for offDayType := range offDayTypes {
+			types = append(types, string(offDayType))
+		}
+		sort.Strings(types)
+		return map[string]interface{}{"type": "string", "enum": types}
+	case daysInputType:
+		// an entry is either a plain date, or an object
+		if _, prs := generator.definitions[t.Name()]; !prs {
+			generator.definitions[t.Name()] = nil
+			generator.definitions[t.Name()] = map[string]interface{}{
+				"oneOf": []interface{}{dateSchema(), generator.structSchema(t)},
+			}
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		// the definition is reserved before it is generated, as types such as tasks are recursive
+		if _, prs := generator.definitions[t.Name()]; !prs {
+			generator.definitions[t.Name()] = nil
+			generator.definitions[t.Name()] = generator.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": generator.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": generator.typeSchema(t.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+func (generator *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	fields := yamlFields(t)
+	properties := make(map[string]interface{}, len(fields))
+	required := make([]string, 0)
+	for _, key := range fieldNames(fields) {
+		field := fields[key]
+		var property map[string]interface{}
+		switch {
+		case dateKeys[key] && indirect(field.Type).Kind() == reflect.String:
+			property = dateSchema()
+		case t == reflect.TypeOf(DaysInput{}) && key == "weekday":
+			property = map[string]interface{}{"type": "string", "enum": weekdayNames()}
+		case key == "holidayRules":
+			property = map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string", "enum": knownHolidayRules()},
+			}
+		default:
+			property = generator.typeSchema(field.Type)
+		}
+		// optional values are written as null when they are not set
+		if field.Type.Kind() == reflect.Ptr {
+			if valueType, ok := property["type"].(string); ok {
+				property["type"] = []string{valueType, "null"}
+			}
+		}
+
+		flags := schemaFlags(field)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		if flags["computed"] {
+			property["description"] = "Computed by planner, and overwritten if filled. " + field.Tag.Get("description")
+			property["readOnly"] = true
+		}
+		if flags["required"] {
+			required = append(required, key)
+		}
+		properties[key] = property
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// schemaFlags are the comma separated flags of the schema tag of the field: required, computed
+func schemaFlags(field reflect.StructField) map[string]bool {
+	flags := make(map[string]bool)
+	for _, flag := range strings.Split(field.Tag.Get("schema"), ",") {
+		if flag != "" {
+			flags[flag] = true
+		}
+	}
+	return flags
+}
+
+func dateSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"pattern":     datePattern,
+		"description": "A date in the format of the planning or in the yyyy-MM-dd format, or a relative date such as today, startDay+10wd or end-of-quarter-2w.",
+	}
+}
+
+// weekdayNames are the weekdays, which are case insensitive, in lower case and capitalized
+func weekdayNames() []string {
+	names := make([]string, 0, 2*len(weekdays))
+	for name, weekday := range weekdays {
+		names = append(names, name, weekday.String())
+	}
+	sort.Strings(names)
+	return names
+}
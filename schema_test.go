@@ -0,0 +1,130 @@
+package planner
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	dat, err := Schema(PlanningFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Properties  map[string]interface{}
+		Required    []string
+		Definitions map[string]struct {
+			Properties map[string]map[string]interface{}
+			Required   []string
+			OneOf      []interface{}
+		}
+	}
+	if err := json.Unmarshal(dat, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, prs := schema.Properties["startDay"]; !prs {
+		t.Errorf("exp the start day in the schema, got %v", schema.Properties)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"startDay"}) {
+		t.Errorf("exp the start day to be required, got %v", schema.Required)
+	}
+	if _, prs := schema.Definitions["TaskInput"].Properties["subtasks"]; !prs {
+		t.Errorf("exp the subtasks in the schema, got %v", schema.Definitions["TaskInput"])
+	}
+	if !reflect.DeepEqual(schema.Definitions["TaskInput"].Required, []string{"name"}) {
+		t.Errorf("exp the name of the tasks to be required, got %v", schema.Definitions["TaskInput"].Required)
+	}
+	if readOnly := schema.Definitions["TaskInput"].Properties["lastDay"]["readOnly"]; readOnly != true {
+		t.Errorf("exp the last day of the tasks to be computed, got %v", schema.Definitions["TaskInput"].Properties["lastDay"])
+	}
+	if len(schema.Definitions["DaysInput"].OneOf) != 2 {
+		t.Errorf("exp days to be a date or an object, got %v", schema.Definitions["DaysInput"])
+	}
+	if _, prs := schema.Definitions["DeveloperInput"].Properties["leaves"]["pattern"]; !prs {
+		t.Errorf("exp a date pattern for leaves, got %v", schema.Definitions["DeveloperInput"].Properties["leaves"])
+	}
+
+	// each kind of file has the fields of its own input type
+	examples := map[string]struct {
+		field    string
+		required bool
+	}{
+		RosterFile:  {field: "holidayRules"},
+		IncludeFile: {field: "tasks"},
+		OverlayFile: {field: "startDay"},
+	}
+	for kind, example := range examples {
+		dat, err := Schema(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fileSchema struct {
+			Properties map[string]interface{}
+			Required   []string
+		}
+		if err := json.Unmarshal(dat, &fileSchema); err != nil {
+			t.Fatal(err)
+		}
+		if _, prs := fileSchema.Properties[example.field]; !prs {
+			t.Errorf("exp %s in the %s schema, got %v", example.field, kind, fileSchema.Properties)
+		}
+		if _, prs := fileSchema.Properties["roster"]; prs && kind != OverlayFile {
+			t.Errorf("exp no roster in the %s schema", kind)
+		}
+		if len(fileSchema.Required) > 0 {
+			t.Errorf("exp nothing required in the %s schema, got %v", kind, fileSchema.Required)
+		}
+	}
+	if _, err := Schema("unknown"); err == nil {
+		t.Errorf("exp an error for an unknown kind of file")
+	}
+
+	// every field of every kind of file is described, and the flags are known ones
+	visited := make(map[reflect.Type]bool)
+	var visit func(typ reflect.Type)
+	visit = func(typ reflect.Type) {
+		typ = sourceType(indirect(typ))
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Map:
+			visit(typ.Elem())
+		case reflect.Struct:
+			if visited[typ] {
+				return
+			}
+			visited[typ] = true
+			for key, field := range yamlFields(typ) {
+				if field.Tag.Get("description") == "" {
+					t.Errorf("exp a description of %s.%s", typ.Name(), key)
+				}
+				for flag := range schemaFlags(field) {
+					if flag != "required" && flag != "computed" {
+						t.Errorf("exp a known schema flag for %s.%s, got %s", typ.Name(), key, flag)
+					}
+				}
+				visit(field.Type)
+			}
+		}
+	}
+	for _, typ := range schemaFileTypes {
+		visit(typ)
+	}
+}
+
+func TestDatePattern(t *testing.T) {
+	pattern := regexp.MustCompile(datePattern)
+	for _, date := range []string{"25/05/1983", "1983-05-25", "05.25.1983", "today", "startDay+10wd", "+3w",
+		"end-of-quarter-2w", "2021-W07-1", "2021-03-01 + 1m"} {
+		if !pattern.MatchString(date) {
+			t.Errorf("exp %s to match", date)
+		}
+	}
+	for _, date := range []string{"", "tomorrow", "25 May 1983", "+3x"} {
+		if pattern.MatchString(date) {
+			t.Errorf("exp %s not to match", date)
+		}
+	}
+}
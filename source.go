@@ -0,0 +1,594 @@
+package planner
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceError is an error at a position of a planning, roster or overlay file.
+// The line and column are 0 when the format of the file does not tell them.
+type SourceError struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (err *SourceError) Error() string {
+	if err.Path == "" {
+		return err.Message
+	}
+	if err.Line == 0 {
+		return fmt.Sprintf("%s: %s", err.Path, err.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", err.Path, err.Line, err.Column, err.Message)
+}
+
+// SourceErrors are all the errors found in planning files, so that they can be fixed at once
+type SourceErrors []*SourceError
+
+// sort orders the errors by position, keeping the files in the order of their first error
+func (errs SourceErrors) sort() {
+	files := make(map[string]int)
+	for _, err := range errs {
+		if _, prs := files[err.Path]; !prs {
+			files[err.Path] = len(files)
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Path != errs[j].Path {
+			return files[errs[i].Path] < files[errs[j].Path]
+		}
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+}
+
+func (errs SourceErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// asSourceErrors returns the errors of a planning file, with the path of the file for the ones that have no position
+func asSourceErrors(path string, err error) SourceErrors {
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case SourceErrors:
+		for _, sourceErr := range err {
+			if sourceErr.Path == "" {
+				sourceErr.Path = path
+			}
+		}
+		return err
+	case *SourceError:
+		return asSourceErrors(path, SourceErrors{err})
+	default:
+		return SourceErrors{{Path: path, Message: err.Error()}}
+	}
+}
+
+// sourcePosition is the position of an input item, such as a developer or a task, in the file it was read from,
+// so that the errors found once the planning is built can be reported there. The line is 0 when the format of the
+// file does not tell it.
+type sourcePosition struct {
+	path   string
+	line   int
+	column int
+}
+
+func (position *sourcePosition) String() string {
+	if position.line == 0 {
+		return position.path
+	}
+	return fmt.Sprintf("%s:%d", position.path, position.line)
+}
+
+func (position *sourcePosition) errorf(format string, args ...interface{}) *SourceError {
+	return &SourceError{
+		Path:    position.path,
+		Line:    position.line,
+		Column:  position.column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// recordPositions records the position of every struct the value points to, in the file it was decoded from.
+// Items that are already recorded keep their position.
+func recordPositions(positions map[interface{}]*sourcePosition, path string, format string, dat []byte, v interface{}) {
+	var node *yaml.Node
+	if format != TOML {
+		var root yaml.Node
+		if err := yaml.Unmarshal(dat, &root); err == nil && len(root.Content) > 0 {
+			node = root.Content[0]
+		}
+	}
+	recordPosition(positions, path, node, reflect.ValueOf(v))
+}
+
+func recordPosition(positions map[interface{}]*sourcePosition, path string, node *yaml.Node, v reflect.Value) {
+	if node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if _, prs := positions[v.Interface()]; !prs && v.Elem().Kind() == reflect.Struct {
+			position := &sourcePosition{path: path}
+			if node != nil {
+				position.line = node.Line
+				position.column = node.Column
+			}
+			positions[v.Interface()] = position
+		}
+		recordPosition(positions, path, node, v.Elem())
+	case reflect.Struct:
+		for key, field := range yamlFields(v.Type()) {
+			recordPosition(positions, path, mappingValue(node, key), v.FieldByIndex(field.Index))
+		}
+	case reflect.Slice:
+		if attributions, ok := v.Interface().(AttributionInputs); ok {
+			for _, attribution := range attributions {
+				recordPosition(positions, path, mappingValue(node, string(attribution.DevId)), reflect.ValueOf(attribution.Attribution))
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			var item *yaml.Node
+			if node != nil && node.Kind == yaml.SequenceNode && i < len(node.Content) {
+				item = node.Content[i]
+			}
+			recordPosition(positions, path, item, v.Index(i))
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			recordPosition(positions, path, mappingValue(node, key.String()), v.MapIndex(key))
+		}
+	}
+}
+
+// developerReference is a developer id used in a planning file, such as in an attribution or a support week,
+// kept with its position until all the developers are known
+type developerReference struct {
+	id     DeveloperId
+	path   string
+	line   int
+	column int
+}
+
+// dateKeys are the fields whose values are dates, whatever the input type they belong to
+var dateKeys = map[string]bool{
+	"startDay": true,
+	"date":     true,
+	"from":     true,
+	"to":       true,
+	"firstDay": true,
+	"lastDay":  true,
+	"deadline": true,
+	"starts":   true,
+	"leaves":   true,
+}
+
+// keySynonyms suggest fields for keys that are not misspelled, but named differently
+var keySynonyms = map[string]string{
+	"end":    "leaves",
+	"ends":   "leaves",
+	"leave":  "leaves",
+	"start":  "starts",
+	"begins": "starts",
+}
+
+var developerIdType = reflect.TypeOf(DeveloperId(""))
+
+var daysInputType = reflect.TypeOf(DaysInput{})
+
+var attributionInputsType = reflect.TypeOf(AttributionInputs{})
+
+// sourceType returns the type of the document tree a value of the type is decoded from. The attributions of a task
+// are an ordered list, written as a table keyed by developer id.
+func sourceType(t reflect.Type) reflect.Type {
+	if t == attributionInputsType {
+		return reflect.TypeOf(map[DeveloperId]*AttributionInput{})
+	}
+	return t
+}
+
+// sourceChecker checks the document tree of a planning file against the input type it is decoded into, and collects
+// all the errors with their position: unknown fields, values of the wrong type and invalid dates. It also collects the
+// developer ids the file refers to.
+type sourceChecker struct {
+	path string
+	// nil when the dates are not checked, as in overlays, whose date format is the one of the planning
+	dates      *dateParser
+	errors     SourceErrors
+	references []*developerReference
+}
+
+func (checker *sourceChecker) errorf(node *yaml.Node, format string, args ...interface{}) {
+	checker.errors = append(checker.errors, &SourceError{
+		Path:    checker.path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (checker *sourceChecker) reference(node *yaml.Node) {
+	checker.references = append(checker.references, &developerReference{
+		id:     DeveloperId(node.Value),
+		path:   checker.path,
+		line:   node.Line,
+		column: node.Column,
+	})
+}
+
+// checkSource checks a planning, roster or overlay file in the YAML, JSON or TOML format, against the input type
+// it is decoded into. The dates are checked with the date parser, unless it is nil. YAML and JSON files are checked
+// as a YAML document tree, which tells the position of the errors, while TOML files are only checked for unknown fields.
+func checkSource(path string, format string, dat []byte, t reflect.Type, dates *dateParser) *sourceChecker {
+	checker := &sourceChecker{path: path, dates: dates}
+
+	if format == TOML {
+		checker.checkTOML(dat, t)
+		return checker
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(dat, &root); err != nil {
+		checker.errors = append(checker.errors, syntaxError(path, err))
+		return checker
+	}
+	if len(root.Content) == 0 {
+		return checker
+	}
+	checker.check(root.Content[0], t, nil)
+	return checker
+}
+
+var yamlLineRegexp = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// syntaxError reports a YAML syntax error at the line it mentions
+func syntaxError(path string, err error) *SourceError {
+	sourceErr := &SourceError{Path: path, Message: err.Error()}
+	if match := yamlLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+		sourceErr.Line, _ = strconv.Atoi(match[1])
+		sourceErr.Message = match[2]
+	}
+	return sourceErr
+}
+
+// check checks a node against the type its value is decoded into. The parent is the struct type the node is a field
+// of, if any.
+func (checker *sourceChecker) check(node *yaml.Node, t reflect.Type, parent reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	t = sourceType(t)
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch {
+	case t == daysInputType && node.Kind == yaml.ScalarNode:
+		checker.checkDate(node)
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		checker.checkFields(node, t)
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if t.Key() == developerIdType {
+				checker.reference(node.Content[i])
+			}
+			checker.check(node.Content[i+1], t.Elem(), nil)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			checker.check(item, t.Elem(), nil)
+		}
+	default:
+		checker.decode(node, t)
+		// developers are defined by their id, and the critical path is written by planner
+		if t == developerIdType && parent != reflect.TypeOf(DeveloperInput{}) && parent != reflect.TypeOf(CriticalStepInput{}) {
+			checker.reference(node)
+		}
+	}
+}
+
+func (checker *sourceChecker) checkFields(node *yaml.Node, t reflect.Type) {
+	fields := yamlFields(t)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		value := node.Content[i+1]
+		field, prs := fields[key.Value]
+		if !prs {
+			checker.errorf(key, "unknown field %s%s", key.Value, didYouMean(key.Value, fieldNames(fields)))
+			continue
+		}
+
+		if dateKeys[key.Value] && indirect(field.Type).Kind() == reflect.String {
+			if value.Kind == yaml.ScalarNode && value.Tag != "!!null" {
+				checker.checkDate(value)
+				continue
+			}
+		}
+		checker.check(value, field.Type, t)
+	}
+}
+
+func (checker *sourceChecker) checkDate(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode {
+		checker.errorf(node, "a date should be a string")
+		return
+	}
+	if checker.dates == nil {
+		return
+	}
+	if _, err := checker.dates.parse(node.Value); err != nil {
+		checker.errorf(node, "%s", err)
+	}
+}
+
+// decode decodes a node into a value of the type, to report the type errors at the position of the node
+func (checker *sourceChecker) decode(node *yaml.Node, t reflect.Type) {
+	err := node.Decode(reflect.New(t).Interface())
+	if err == nil {
+		return
+	}
+
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		checker.errorf(node, "%s", err)
+		return
+	}
+	for _, message := range typeErr.Errors {
+		if match := yamlLineRegexp.FindStringSubmatch(message); match != nil {
+			message = match[2]
+		}
+		checker.errorf(node, "%s", message)
+	}
+}
+
+// checkTOML reports the keys of a TOML file that are not fields of the input type
+func (checker *sourceChecker) checkTOML(dat []byte, t reflect.Type) {
+	meta, err := toml.Decode(string(dat), reflect.New(t).Interface())
+	if err != nil {
+		sourceErr := &SourceError{Path: checker.path, Message: err.Error()}
+		if parseErr, ok := err.(toml.ParseError); ok {
+			sourceErr.Line = parseErr.Position.Line
+			sourceErr.Column = parseErr.Position.Col
+			sourceErr.Message = parseErr.Message
+		}
+		checker.errors = append(checker.errors, sourceErr)
+		return
+	}
+
+	// the tables decoded by the types themselves, such as attributions, are not listed as undecoded, so all the keys
+	// are checked against the fields
+	for _, key := range meta.Keys() {
+		fields := tomlFieldsAt(t, key[:len(key)-1])
+		name := key[len(key)-1]
+		if fields == nil {
+			// the key is within a map, or within a field that is unknown itself, which is already reported
+			continue
+		}
+		if _, prs := fields[name]; prs {
+			continue
+		}
+		checker.errors = append(checker.errors, &SourceError{
+			Path:    checker.path,
+			Message: fmt.Sprintf("unknown field %s%s", key, didYouMean(name, fieldNames(fields))),
+		})
+	}
+}
+
+// tomlFieldsAt returns the fields of the struct type found at the key path, or nil if there is none
+func tomlFieldsAt(t reflect.Type, path []string) map[string]reflect.StructField {
+	for {
+		t = sourceType(indirect(t))
+		if t.Kind() == reflect.Slice {
+			t = t.Elem()
+			continue
+		}
+		if t.Kind() == reflect.Map {
+			if len(path) == 0 {
+				return nil
+			}
+			t = t.Elem()
+			path = path[1:]
+			continue
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+
+		fields := yamlFields(t)
+		if len(path) == 0 {
+			return fields
+		}
+		field, prs := fields[path[0]]
+		if !prs {
+			return nil
+		}
+		t = field.Type
+		path = path[1:]
+	}
+}
+
+// yamlFields returns the fields of a struct type by key. The keys are the same in all formats.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func fieldNames(fields map[string]reflect.StructField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// documentDates returns the date parser of a planning document, with its date format and start day. Without a valid
+// date format or start day, the dates are checked with the default format, relative to today.
+func documentDates(dat []byte, format string) *dateParser {
+	var input struct {
+		DateFormat string `yaml:"dateFormat" json:"dateFormat" toml:"dateFormat"`
+		StartDay   string `yaml:"startDay" json:"startDay" toml:"startDay"`
+	}
+	_ = Unmarshal(format, dat, &input)
+
+	layout := dateFormat
+	if input.DateFormat != "" {
+		if inputLayout, err := DateFormatLayout(input.DateFormat); err == nil {
+			layout = inputLayout
+		}
+	}
+
+	dates := newDateParser(layout)
+	startDay, err := dates.parse(input.StartDay)
+	if err != nil {
+		startDay = dates.today
+	}
+	dates.startDay = &startDay
+	return dates
+}
+
+// checkReferences reports the developer ids used in the planning files that are not the id of any developer
+func checkReferences(references []*developerReference, developers []*DeveloperInput) error {
+	ids := make([]string, len(developers))
+	known := make(map[DeveloperId]bool, len(developers))
+	for i, developer := range developers {
+		ids[i] = string(developer.Id)
+		known[developer.Id] = true
+	}
+
+	errs := make(SourceErrors, 0)
+	for _, reference := range references {
+		if known[reference.id] {
+			continue
+		}
+		errs = append(errs, &SourceError{
+			Path:    reference.path,
+			Line:    reference.line,
+			Column:  reference.column,
+			Message: fmt.Sprintf("unknown developer %s%s", reference.id, didYouMean(string(reference.id), ids)),
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// didYouMean suggests the candidate closest to a misspelled word, if any is close enough
+func didYouMean(word string, candidates []string) string {
+	if synonym, prs := keySynonyms[word]; prs {
+		for _, candidate := range candidates {
+			if candidate == synonym {
+				return fmt.Sprintf(", did you mean %s?", synonym)
+			}
+		}
+	}
+
+	best := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(word), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	if best == "" || bestDistance > max(1, len(word)/3) {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", best)
+}
+
+// editDistance is the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// needed to turn a string into the other
+func editDistance(a string, b string) int {
+	distances := make([][]int, len(a)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(b)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			distances[i][j] = min(distances[i-1][j]+1, distances[i][j-1]+1, distances[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				distances[i][j] = min(distances[i][j], distances[i-2][j-2]+1)
+			}
+		}
+	}
+	return distances[len(a)][len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, value := range values[1:] {
+		if value < m {
+			m = value
+		}
+	}
+	return m
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
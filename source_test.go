@@ -0,0 +1,90 @@
+package planner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadPlanningInputErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "planning.yaml")
+	doc := `startDay: 04/01/2021
+holidays:
+  - 32/01/2021
+developers:
+  - id: Alice
+    ends: 01/03/2021
+    utilization: lots
+supportWeeks:
+  - firstDay: 04/01/2021
+    lastDay: 08/01/2021
+    devId: Alcie
+tasks:
+  - name: A
+    attributions:
+      Alice:
+        efort: 2
+`
+	if err := ioutil.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReadPlanningInput(path)
+	errs, ok := err.(SourceErrors)
+	if !ok {
+		t.Fatalf("exp source errors, got %v", err)
+	}
+
+	exp := []string{
+		path + ":3:5: error parsing date 32/01/2021, should be in the format 25/05/1983 or 1983-05-25",
+		path + ":6:5: unknown field ends, did you mean leaves?",
+		path + ":7:18: cannot unmarshal !!str `lots` into float64",
+		path + ":11:12: unknown developer Alcie, did you mean Alice?",
+		path + ":16:9: unknown field efort, did you mean effort?",
+	}
+	if len(errs) != len(exp) {
+		t.Fatalf("exp %d errors, got %d: %s", len(exp), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != exp[i] {
+			t.Errorf("exp %s, got %s", exp[i], err)
+		}
+	}
+}
+
+func TestCheckSourceTOML(t *testing.T) {
+	doc := `
+startDay = "04/01/2021"
+
+[[developers]]
+id = "Alice"
+offDays = ["05/01/2021", { date = "06/01/2021", type = "sick" }]
+leves = "01/03/2021"
+`
+	checker := checkSource("planning.toml", TOML, []byte(doc), reflect.TypeOf(PlanningInput{}), nil)
+	if len(checker.errors) != 1 || checker.errors[0].Error() != "planning.toml: unknown field developers.leves, did you mean leaves?" {
+		t.Errorf("exp an unknown field error, got %v", checker.errors)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	candidates := []string{"firstDay", "lastDay", "deadline"}
+	tests := map[string]string{
+		"lastday":  ", did you mean lastDay?",
+		"fristDay": ", did you mean firstDay?",
+		"weight":   "",
+	}
+	for word, exp := range tests {
+		if got := didYouMean(word, candidates); got != exp {
+			t.Errorf("%s: exp %q, got %q", word, exp, got)
+		}
+	}
+}